@@ -13,8 +13,11 @@ import (
 	"time"
 
 	v1 "github.com/moemoeq/tyk-sre-app/internal/api/v1"
+	"github.com/moemoeq/tyk-sre-app/internal/api/v1/network"
 	"github.com/moemoeq/tyk-sre-app/internal/config"
+	"github.com/moemoeq/tyk-sre-app/internal/history"
 	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/moemoeq/tyk-sre-app/internal/notify"
 	"github.com/moemoeq/tyk-sre-app/internal/server"
 )
 
@@ -23,24 +26,79 @@ func main() {
 	fmt.Printf("APP Environment: %s\n", cfg.Environment)
 
 	kubeconfig := flag.String("kubeconfig", "", "path to kubeconfig, leave empty for in-cluster")
+	clusterName := flag.String("cluster-name", "default", "name of the --kubeconfig cluster when CLUSTERS_FILE is not set")
 	address := flag.String("address", ":"+cfg.Port, "HTTP server listen address")
 	flag.Parse()
 
-	kClient, err := k8s.NewClient(*kubeconfig)
+	clusters, err := newClusters(cfg, *kubeconfig, *clusterName)
 	if err != nil {
 		panic(err)
 	}
 
-	version, err := k8s.GetKubernetesVersion(kClient.Clientset)
-	if err != nil {
-		panic(err)
+	// the default cluster must be up, the others may come back later
+	for _, name := range clusters.Names() {
+		kClient, _ := clusters.Get(name)
+		version, err := k8s.GetKubernetesVersion(kClient.Clientset)
+		if err != nil {
+			if name == clusters.Default() {
+				panic(err)
+			}
+			fmt.Printf("Cluster %s unreachable: %v\n", name, err)
+			continue
+		}
+		fmt.Printf("Connected to Kubernetes %s (cluster %s)\n", version, name)
 	}
-	fmt.Printf("Connected to Kubernetes %s\n", version)
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	apiV1 := v1.New(cfg, kClient)
+	if cfg.CacheEnabled {
+		for _, name := range clusters.Names() {
+			kClient, _ := clusters.Get(name)
+			kClient.StartCache(ctx, time.Duration(cfg.CacheResync)*time.Second)
+		}
+	}
+
+	apiV1 := v1.New(cfg, clusters)
+	if cfg.HistoryEnabled {
+		store, err := startHistory(ctx, cfg, clusters)
+		if err != nil {
+			// the rest of the API works without history
+			fmt.Printf("Health history disabled: %v\n", err)
+		} else {
+			defer store.Close()
+			apiV1.History = store
+		}
+	}
+	notifier, err := newNotifier(cfg)
+	if err != nil {
+		panic(err)
+	}
+	if len(notifier.Sinks) > 0 {
+		notifier.Start(ctx)
+		for _, name := range clusters.Names() {
+			kClient, _ := clusters.Get(name)
+			monitor := &notify.Monitor{
+				Notifier: notifier,
+				Cluster:  name,
+				Client:   kClient,
+				Interval: time.Duration(cfg.NotifyInterval) * time.Second,
+			}
+			go monitor.Run(ctx)
+		}
+	}
+
+	// time-limited network blocks
+	for _, name := range clusters.Names() {
+		kClient, _ := clusters.Get(name)
+		reaper := &network.Reaper{
+			Client:   kClient,
+			Cluster:  name,
+			Interval: time.Duration(cfg.NetworkBlockReapInterval) * time.Second,
+		}
+		go reaper.Run(ctx)
+	}
+
 	srv := server.New(ctx, *address, apiV1)
 
 	// Start Server in a separate goroutine
@@ -66,3 +124,87 @@ func main() {
 
 	fmt.Println("Server exiting")
 }
+
+// newClusters builds the client registry from CLUSTERS_FILE, or from --kubeconfig alone.
+func newClusters(cfg *config.Config, kubeconfig, clusterName string) (*k8s.Registry, error) {
+	if cfg.ClustersFile == "" {
+		if err := config.ValidateClusterName(clusterName); err != nil {
+			return nil, err
+		}
+		kClient, err := k8s.NewClient(kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		clusters := k8s.NewRegistry(clusterName)
+		clusters.Add(clusterName, kClient)
+		return clusters, nil
+	}
+
+	file, err := config.LoadClusters(cfg.ClustersFile)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := k8s.NewRegistry(file.Default)
+	for _, c := range file.Clusters {
+		kClient, err := k8s.NewClientForContext(c.Kubeconfig, c.Context)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", c.Name, err)
+		}
+		clusters.Add(c.Name, kClient)
+	}
+	return clusters, nil
+}
+
+// startHistory opens the history store in DATA_DIR and samples every cluster in the background.
+func startHistory(ctx context.Context, cfg *config.Config, clusters *k8s.Registry) (*history.Store, error) {
+	store, err := history.Open(cfg.DataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range clusters.Names() {
+		kClient, _ := clusters.Get(name)
+		sampler := &history.Sampler{
+			Store:     store,
+			Cluster:   name,
+			Client:    kClient,
+			Interval:  time.Duration(cfg.HistorySampleInterval) * time.Second,
+			Retention: time.Duration(cfg.HistoryRetentionDays) * 24 * time.Hour,
+		}
+		go sampler.Run(ctx)
+	}
+	return store, nil
+}
+
+// newNotifier builds the notification sinks configured in NOTIFY_*.
+func newNotifier(cfg *config.Config) (*notify.Notifier, error) {
+	notifier := &notify.Notifier{
+		Dedup:   time.Duration(cfg.NotifyDedupWindow) * time.Second,
+		Retries: cfg.NotifyRetries,
+		Backoff: time.Duration(cfg.NotifyRetryBackoff) * time.Second,
+	}
+
+	for _, url := range cfg.NotifyWebhookUrls {
+		sink, err := notify.NewWebhookSink(url, cfg.NotifyWebhookTemplate)
+		if err != nil {
+			return nil, err
+		}
+		notifier.Sinks = append(notifier.Sinks, sink)
+	}
+	for _, url := range cfg.NotifySlackUrls {
+		sink, err := notify.NewSlackSink(url, cfg.NotifySlackTemplate)
+		if err != nil {
+			return nil, err
+		}
+		notifier.Sinks = append(notifier.Sinks, sink)
+	}
+	for _, url := range cfg.NotifyAlertmanagerUrls {
+		sink, err := notify.NewAlertmanagerSink(url, cfg.NotifyAlertmanagerTemplate)
+		if err != nil {
+			return nil, err
+		}
+		notifier.Sinks = append(notifier.Sinks, sink)
+	}
+	return notifier, nil
+}
@@ -1,20 +1,28 @@
 package v1
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 
 	"github.com/moemoeq/tyk-sre-app/internal/api/v1/network"
 	"github.com/moemoeq/tyk-sre-app/internal/config"
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	"github.com/moemoeq/tyk-sre-app/internal/history"
 	"github.com/moemoeq/tyk-sre-app/internal/k8s"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type API struct {
-	Config    *config.Config
+	Config *config.Config
+	// Client of the default cluster
 	K8sClient *k8s.Client
+	// Clients selectable with ?cluster= or /clusters/{cluster}/, nil serves K8sClient only
+	Clusters *k8s.Registry
+	// Health transitions recorded by the sampler, nil when history is disabled
+	History *history.Store
 }
 
 // EnrichedDeployment wraps appsv1.Deployment with health information.
@@ -24,23 +32,94 @@ type EnrichedDeployment struct {
 	Spec              *appsv1.DeploymentSpec  `json:"spec,omitempty"`
 	Status            appsv1.DeploymentStatus `json:"status"`
 	Health            bool                    `json:"health"`
+	Degraded          bool                    `json:"degraded,omitempty"`
+	HealthReasons     []health.Reason         `json:"health_reasons"`
+	DegradedReasons   []health.Reason         `json:"degraded_reasons,omitempty"`
+	HPA               *health.HPAStatus       `json:"hpa,omitempty"`
+	Events            []health.EventSummary   `json:"events,omitempty"`
 }
 
-func New(cfg *config.Config, k8sClient *k8s.Client) *API {
+// EnrichedStatefulSet wraps appsv1.StatefulSet with health information.
+type EnrichedStatefulSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              *appsv1.StatefulSetSpec  `json:"spec,omitempty"`
+	Status            appsv1.StatefulSetStatus `json:"status"`
+	Health            bool                     `json:"health"`
+	HealthReasons     []health.Reason          `json:"health_reasons"`
+}
+
+// EnrichedDaemonSet wraps appsv1.DaemonSet with health information.
+type EnrichedDaemonSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              *appsv1.DaemonSetSpec  `json:"spec,omitempty"`
+	Status            appsv1.DaemonSetStatus `json:"status"`
+	Health            bool                   `json:"health"`
+	HealthReasons     []health.Reason        `json:"health_reasons"`
+}
+
+// EnrichedReplicaSet wraps appsv1.ReplicaSet with health information.
+type EnrichedReplicaSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              *appsv1.ReplicaSetSpec  `json:"spec,omitempty"`
+	Status            appsv1.ReplicaSetStatus `json:"status"`
+	Health            bool                    `json:"health"`
+	HealthReasons     []health.Reason         `json:"health_reasons"`
+}
+
+func New(cfg *config.Config, clusters *k8s.Registry) *API {
+	k8sClient, _ := clusters.Get("")
 	return &API{
 		Config:    cfg,
 		K8sClient: k8sClient,
+		Clusters:  clusters,
 	}
 }
 
-// Register API routes
+// Register API routes, also served per cluster under /clusters/{cluster}/
 func (api *API) Register(mux *http.ServeMux) {
+	api.routes(mux)
+
+	clusterMux := http.NewServeMux()
+	api.routes(clusterMux)
+	mux.Handle("/clusters/{cluster}/", api.withCluster(clusterMux))
+	mux.Handle("GET /clusters", api.wrap(api.getClusters))
+}
+
+func (api *API) routes(mux *http.ServeMux) {
 	mux.Handle("/deployments", api.wrap(api.getDeployments))
+	mux.Handle("GET /deployments/watch", api.wrap(api.watchDeployments))
+	mux.Handle("GET /deployments/{namespace}/{name}/pods", api.wrap(api.getDeploymentPods))
+	mux.Handle("GET /deployments/{namespace}/{name}/events", api.wrap(api.getDeploymentEvents))
+	mux.Handle("GET /deployments/{namespace}/{name}/history", api.wrap(api.getDeploymentHistory))
+	mux.Handle("GET /deployments/{namespace}/{name}/timeline", api.wrap(api.getDeploymentTimeline))
+	mux.Handle("POST /deployments/{namespace}/{name}/restart", api.wrap(api.restartDeployment))
+	mux.Handle("POST /deployments/{namespace}/{name}/rollback", api.wrap(api.rollbackDeployment))
+	mux.Handle("POST /deployments/{namespace}/{name}/pause", api.wrap(api.pauseDeployment))
+	mux.Handle("POST /deployments/{namespace}/{name}/resume", api.wrap(api.resumeDeployment))
+	mux.Handle("PUT /deployments/{namespace}/{name}/scale", api.wrap(api.scaleDeployment))
+	mux.Handle("/statefulsets", api.wrap(api.getStatefulSets))
+	mux.Handle("/daemonsets", api.wrap(api.getDaemonSets))
+	mux.Handle("/replicasets", api.wrap(api.getReplicaSets))
+	mux.Handle("/workloads", api.wrap(api.getWorkloads))
+	mux.Handle("/health/summary", api.wrap(api.getHealthSummary))
+	mux.Handle("GET /slo", api.wrap(api.getSLO))
 	mux.Handle("/reachability", api.wrap(api.checkK8sReachability))
+	mux.Handle("GET /audit/pdb", api.wrap(api.getPDBAudit))
+	mux.Handle("GET /audit/workloads", api.wrap(api.getWorkloadAudit))
+	mux.Handle("GET /audit/security", api.wrap(api.getSecurityAudit))
 
 	// TODO: refactor network route into subrouter
 	// Network Handlers
-	netHandler := &network.Handler{K8sClient: api.K8sClient}
+	// the handler picks up the cluster selected in the request context
+	netHandler := &network.Handler{
+		K8sClient:        api.K8sClient,
+		PodCIDRs:         api.Config.NetworkPodCidrs,
+		PodWarnThreshold: api.Config.NetworkBlockPodWarnThreshold,
+		TrustedProxies:   api.Config.NetworkTrustedProxyCidrs,
+	}
 	mux.Handle("/network/policies", api.wrap(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodGet {
 			netHandler.ListPolicies(w, r)
@@ -50,6 +129,9 @@ func (api *API) Register(mux *http.ServeMux) {
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		}
 	}))
+	mux.Handle("GET /network/blocks", api.wrap(netHandler.ListBlocks))
+	mux.Handle("GET /network/blocks/{id}", api.wrap(netHandler.GetBlock))
+	mux.Handle("DELETE /network/blocks/{id}", api.wrap(netHandler.DeleteBlock))
 	mux.Handle("/network/block", api.wrap(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodPost {
 			netHandler.BlockWorkloads(w, r)
@@ -66,10 +148,58 @@ func (api *API) wrap(h http.HandlerFunc) http.Handler {
 		// Common headers
 		w.Header().Set("Content-Type", "application/json")
 
+		// Bypass the informer cache and read from the API server
+		if r.URL.Query().Get("consistent") == "true" {
+			r = r.WithContext(k8s.WithConsistentRead(r.Context()))
+		}
+
+		// ?cluster=, unless already selected by the /clusters/{cluster}/ prefix
+		if name := r.URL.Query().Get("cluster"); name != "" {
+			if _, selected := k8s.ClientFrom(r.Context()); !selected {
+				client, ok := api.cluster(name)
+				if !ok {
+					api.respondError(w, http.StatusNotFound, fmt.Sprintf("unknown cluster %s", name))
+					return
+				}
+				r = r.WithContext(k8s.WithClient(r.Context(), client))
+			}
+		}
+
 		h(w, r)
 	})
 }
 
+// withCluster serves /clusters/{cluster}/... with the routes of the default cluster.
+func (api *API) withCluster(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("cluster")
+		client, ok := api.cluster(name)
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			api.respondError(w, http.StatusNotFound, fmt.Sprintf("unknown cluster %s", name))
+			return
+		}
+
+		r = r.WithContext(k8s.WithClient(r.Context(), client))
+		http.StripPrefix("/clusters/"+name, h).ServeHTTP(w, r)
+	})
+}
+
+func (api *API) cluster(name string) (*k8s.Client, bool) {
+	if api.Clusters == nil {
+		return nil, false
+	}
+	return api.Clusters.Get(name)
+}
+
+// client returns the cluster client selected by the request, the default cluster otherwise.
+func (api *API) client(ctx context.Context) *k8s.Client {
+	if c, ok := k8s.ClientFrom(ctx); ok {
+		return c
+	}
+	return api.K8sClient
+}
+
 func (a *API) respondJSON(w http.ResponseWriter, status int, payload interface{}) {
 	w.WriteHeader(status)
 	if payload != nil {
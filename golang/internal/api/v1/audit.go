@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/moemoeq/tyk-sre-app/internal/audit"
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PodDisruptionBudget coverage of Deployments and StatefulSets,
+// and PDBs that would block or miss a node drain.
+func (api *API) getPDBAudit(w http.ResponseWriter, r *http.Request) {
+	namespace := r.URL.Query().Get("namespace")
+	opts := metav1.ListOptions{}
+
+	deployments, err := api.client(r.Context()).ListDeployments(r.Context(), namespace, opts)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	statefulSets, err := api.client(r.Context()).ListStatefulSets(r.Context(), namespace, opts)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	pdbs, err := api.client(r.Context()).ListPodDisruptionBudgets(r.Context(), namespace, opts)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	pods, err := api.client(r.Context()).ListPods(r.Context(), namespace, opts)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.respondJSON(w, http.StatusOK, audit.AuditPDBs(deployments, statefulSets, pdbs, pods))
+}
+
+// Best-practice findings on Deployment pod templates, as JSON or SARIF (?format=sarif).
+func (api *API) getWorkloadAudit(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "sarif" {
+		api.respondError(w, http.StatusBadRequest, "invalid format, expected json|sarif")
+		return
+	}
+
+	namespace, listOptions := listQuery(r)
+	deployments, err := api.client(r.Context()).ListDeployments(r.Context(), namespace, listOptions)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	opts := audit.WorkloadOptions{AllowedRegistries: api.Config.AuditAllowedRegistries}
+	findings := []audit.Finding{}
+	for _, d := range deployments {
+		ref := health.Ref{Kind: health.KindDeployment, Namespace: d.Namespace, Name: d.Name}
+		findings = append(findings, audit.AuditPodSpec(ref, &d.Spec.Template.Spec, opts)...)
+	}
+	report := audit.NewWorkloadReport(findings)
+
+	if format == "sarif" {
+		w.Header().Set("Content-Type", "application/sarif+json")
+		api.respondJSON(w, http.StatusOK, audit.ToSarif(report.Findings))
+		return
+	}
+	api.respondJSON(w, http.StatusOK, report)
+}
+
+// Pod Security Standards level of every workload and the admission labels of namespaces.
+func (api *API) getSecurityAudit(w http.ResponseWriter, r *http.Request) {
+	namespace, listOptions := listQuery(r)
+
+	workloads := []audit.PodSecurityResult{}
+	deployments, err := api.client(r.Context()).ListDeployments(r.Context(), namespace, listOptions)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, d := range deployments {
+		ref := health.Ref{Kind: health.KindDeployment, Namespace: d.Namespace, Name: d.Name}
+		workloads = append(workloads, audit.EvaluatePodSecurity(ref, &d.Spec.Template.Spec))
+	}
+
+	statefulSets, err := api.client(r.Context()).ListStatefulSets(r.Context(), namespace, listOptions)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, s := range statefulSets {
+		ref := health.Ref{Kind: health.KindStatefulSet, Namespace: s.Namespace, Name: s.Name}
+		workloads = append(workloads, audit.EvaluatePodSecurity(ref, &s.Spec.Template.Spec))
+	}
+
+	daemonSets, err := api.client(r.Context()).ListDaemonSets(r.Context(), namespace, listOptions)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, s := range daemonSets {
+		ref := health.Ref{Kind: health.KindDaemonSet, Namespace: s.Namespace, Name: s.Name}
+		workloads = append(workloads, audit.EvaluatePodSecurity(ref, &s.Spec.Template.Spec))
+	}
+
+	namespaces, err := api.client(r.Context()).ListNamespaces(r.Context(), metav1.ListOptions{})
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	postures := []audit.NamespacePosture{}
+	for _, ns := range namespaces {
+		if namespace == "" || ns.Name == namespace {
+			postures = append(postures, audit.InspectNamespace(&ns))
+		}
+	}
+
+	api.respondJSON(w, http.StatusOK, audit.NewSecurityReport(workloads, postures))
+}
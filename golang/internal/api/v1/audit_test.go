@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moemoeq/tyk-sre-app/internal/audit"
+	"github.com/moemoeq/tyk-sre-app/internal/config"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetPDBAudit(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "stale", Namespace: "default"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "gone"}}},
+		},
+	)
+	api := &API{Config: &config.Config{}, K8sClient: &k8s.Client{Clientset: clientset}}
+	mux := http.NewServeMux()
+	api.Register(mux)
+
+	req, _ := http.NewRequest("GET", "/audit/pdb?namespace=default", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var report audit.PDBReport
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Len(t, report.Uncovered, 1)
+	assert.Len(t, report.NoPods, 1)
+	assert.Empty(t, report.ZeroDisruptions)
+}
+
+func TestGetWorkloadAudit(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "quay.io/x/app:latest"}},
+		}}},
+	})
+	api := &API{
+		Config:    &config.Config{AuditAllowedRegistries: []string{"ghcr.io"}},
+		K8sClient: &k8s.Client{Clientset: clientset},
+	}
+	mux := http.NewServeMux()
+	api.Register(mux)
+
+	req, _ := http.NewRequest("GET", "/audit/workloads", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var report audit.WorkloadReport
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, 2, report.Counts[audit.SeverityError]) // latest tag, registry
+
+	req, _ = http.NewRequest("GET", "/audit/workloads?format=sarif", nil)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/sarif+json", rr.Header().Get("Content-Type"))
+	var log audit.SarifLog
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &log))
+	assert.Len(t, log.Runs[0].Results, len(report.Findings))
+
+	req, _ = http.NewRequest("GET", "/audit/workloads?format=xml", nil)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestGetSecurityAudit(t *testing.T) {
+	privileged := true
+	clientset := fake.NewSimpleClientset(
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-agent", Namespace: "monitoring"},
+			Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				HostPID:    true,
+				Containers: []corev1.Container{{Name: "agent", SecurityContext: &corev1.SecurityContext{Privileged: &privileged}}},
+			}}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app"}},
+			}}},
+		},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{audit.EnforceLabel: audit.LevelBaseline}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "monitoring"}},
+	)
+	api := &API{Config: &config.Config{}, K8sClient: &k8s.Client{Clientset: clientset}}
+	mux := http.NewServeMux()
+	api.Register(mux)
+
+	req, _ := http.NewRequest("GET", "/audit/security", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var report audit.SecurityReport
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	if assert.Len(t, report.Workloads, 2) {
+		// least secure first
+		assert.Equal(t, "node-agent", report.Workloads[0].Name)
+		assert.Equal(t, audit.LevelPrivileged, report.Workloads[0].Level)
+		assert.Equal(t, audit.LevelBaseline, report.Workloads[1].Level)
+	}
+	assert.Equal(t, []audit.NamespacePosture{
+		{Name: "default", Enforce: audit.LevelBaseline, Enforced: true},
+		{Name: "monitoring"},
+	}, report.Namespaces)
+
+	req, _ = http.NewRequest("GET", "/audit/security?namespace=monitoring", nil)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Len(t, report.Workloads, 1)
+	assert.Len(t, report.Namespaces, 1)
+}
@@ -0,0 +1,48 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+)
+
+// clusterCheckTimeout bounds the reachability check of a single cluster.
+var clusterCheckTimeout = 5 * time.Second
+
+// ClusterStatus is a configured cluster and its reachability.
+type ClusterStatus struct {
+	Name         string                 `json:"name"`
+	Default      bool                   `json:"default"`
+	Reachability k8s.ReachabilityStatus `json:"reachability"`
+}
+
+// Lists the configured clusters, checked concurrently so one unreachable cluster does not stall the rest.
+func (api *API) getClusters(w http.ResponseWriter, r *http.Request) {
+	if api.Clusters == nil {
+		api.respondJSON(w, http.StatusOK, []ClusterStatus{})
+		return
+	}
+
+	names := api.Clusters.Names()
+	response := make([]ClusterStatus, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		client, _ := api.Clusters.Get(name)
+		response[i] = ClusterStatus{Name: name, Default: name == api.Clusters.Default()}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(r.Context(), clusterCheckTimeout)
+			defer cancel()
+			response[i].Reachability = client.CheckConnectivity(ctx)
+		}()
+	}
+	wg.Wait()
+
+	api.respondJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moemoeq/tyk-sre-app/internal/api/v1/network"
+	"github.com/moemoeq/tyk-sre-app/internal/config"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	disco "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func clustersAPI() *API {
+	prod := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "prod-web", Namespace: "default"}},
+		&networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "prod-policy", Namespace: "default"}},
+	)
+	prod.Discovery().(*disco.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.29.0"}
+	staging := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "staging-web", Namespace: "default"}},
+		&networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "staging-policy", Namespace: "default"}},
+	)
+	staging.Discovery().(*disco.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.28.0"}
+
+	clusters := k8s.NewRegistry("prod")
+	clusters.Add("prod", &k8s.Client{Clientset: prod})
+	clusters.Add("staging", &k8s.Client{Clientset: staging})
+	return New(&config.Config{}, clusters)
+}
+
+func TestClusterSelection(t *testing.T) {
+	mux := http.NewServeMux()
+	clustersAPI().Register(mux)
+
+	tests := []struct {
+		path string
+		code int
+		want string
+	}{
+		{"/deployments", http.StatusOK, "prod-web"},
+		{"/deployments?cluster=staging", http.StatusOK, "staging-web"},
+		{"/clusters/staging/deployments", http.StatusOK, "staging-web"},
+		{"/clusters/prod/deployments", http.StatusOK, "prod-web"},
+		// the prefix wins over the query
+		{"/clusters/prod/deployments?cluster=staging", http.StatusOK, "prod-web"},
+		{"/clusters/staging/network/policies", http.StatusOK, "staging-policy"},
+		{"/network/policies?cluster=staging", http.StatusOK, "staging-policy"},
+		{"/deployments?cluster=dev", http.StatusNotFound, "unknown cluster dev"},
+		{"/clusters/dev/deployments", http.StatusNotFound, "unknown cluster dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.want)
+		})
+	}
+}
+
+func TestGetClusters(t *testing.T) {
+	mux := http.NewServeMux()
+	clustersAPI().Register(mux)
+
+	req, _ := http.NewRequest("GET", "/clusters", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var clusters []ClusterStatus
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &clusters))
+	if assert.Len(t, clusters, 2) {
+		assert.Equal(t, "prod", clusters[0].Name)
+		assert.True(t, clusters[0].Default)
+		assert.Equal(t, "v1.29.0", clusters[0].Reachability.Version)
+		assert.Equal(t, "staging", clusters[1].Name)
+		assert.False(t, clusters[1].Default)
+		assert.Equal(t, "v1.28.0", clusters[1].Reachability.Version)
+	}
+}
+
+// Handlers built without a registry keep serving their single client.
+func TestClusterSelection_NoRegistry(t *testing.T) {
+	api := &API{Config: &config.Config{}, K8sClient: &k8s.Client{Clientset: fake.NewSimpleClientset()}}
+	mux := http.NewServeMux()
+	api.Register(mux)
+
+	req, _ := http.NewRequest("GET", "/deployments?cluster=prod", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	h := &network.Handler{K8sClient: api.K8sClient}
+	req, _ = http.NewRequest("GET", "/network/policies", nil)
+	rr = httptest.NewRecorder()
+	h.ListPolicies(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
@@ -0,0 +1,125 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DeploymentEvents is the event view of a single Deployment.
+type DeploymentEvents struct {
+	Namespace string                `json:"namespace"`
+	Name      string                `json:"name"`
+	Health    bool                  `json:"health"`
+	Events    []health.EventSummary `json:"events"`
+}
+
+// Events of the deployment, its ReplicaSets and its pods, deduplicated by reason.
+func (api *API) getDeploymentEvents(w http.ResponseWriter, r *http.Request) {
+	d, err := api.client(r.Context()).GetDeployment(r.Context(), r.PathValue("namespace"), r.PathValue("name"))
+	if err != nil {
+		api.respondError(w, errorStatus(err), err.Error())
+		return
+	}
+
+	events, err := api.deploymentEvents(r.Context(), d.Namespace, []appsv1.Deployment{*d}, eventTypeQuery(r))
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.respondJSON(w, http.StatusOK, DeploymentEvents{
+		Namespace: d.Namespace,
+		Name:      d.Name,
+		Health:    health.EvaluateDeployment(d).Healthy,
+		Events:    events[d.UID],
+	})
+}
+
+// eventTypeQuery reads ?eventType=, Warning by default and "all" for every type.
+func eventTypeQuery(r *http.Request) string {
+	switch t := r.URL.Query().Get("eventType"); t {
+	case "":
+		return corev1.EventTypeWarning
+	case "all":
+		return ""
+	default:
+		return t
+	}
+}
+
+// deploymentEvents correlates the events in namespace to deployments by involved object UID,
+// following Deployment -> ReplicaSet -> Pod ownership. Keyed by Deployment UID.
+func (api *API) deploymentEvents(ctx context.Context, namespace string, deployments []appsv1.Deployment, eventType string) (map[types.UID][]health.EventSummary, error) {
+	// involved object UID -> deployment UID
+	owner := make(map[types.UID]types.UID, len(deployments))
+	// "namespace/replicaset" -> deployment UID, for pods that are already gone
+	replicaSetNames := map[string]types.UID{}
+	for _, d := range deployments {
+		owner[d.UID] = d.UID
+	}
+
+	replicaSets, err := api.client(ctx).ListReplicaSets(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, rs := range replicaSets {
+		if ref := metav1.GetControllerOf(&rs); ref != nil {
+			if d, ok := owner[ref.UID]; ok {
+				owner[rs.UID] = d
+				replicaSetNames[rs.Namespace+"/"+rs.Name] = d
+			}
+		}
+	}
+
+	pods, err := api.client(ctx).ListPods(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pods {
+		if ref := metav1.GetControllerOf(&p); ref != nil {
+			if d, ok := owner[ref.UID]; ok {
+				owner[p.UID] = d
+			}
+		}
+	}
+
+	events, err := api.client(ctx).ListEvents(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	grouped := map[types.UID][]corev1.Event{}
+	for _, e := range events {
+		if d, ok := owner[e.InvolvedObject.UID]; ok {
+			grouped[d] = append(grouped[d], e)
+		} else if d, ok := deletedPodOwner(e.InvolvedObject, replicaSetNames); ok {
+			grouped[d] = append(grouped[d], e)
+		}
+	}
+
+	result := make(map[types.UID][]health.EventSummary, len(deployments))
+	for _, d := range deployments {
+		result[d.UID] = health.SummarizeEvents(grouped[d.UID], eventType)
+	}
+	return result, nil
+}
+
+// deletedPodOwner matches a pod by its generated name "<replicaset>-<suffix>",
+// e.g. BackOff events outlive the crash looping pods they were recorded on.
+func deletedPodOwner(ref corev1.ObjectReference, replicaSetNames map[string]types.UID) (types.UID, bool) {
+	if ref.Kind != "Pod" {
+		return "", false
+	}
+	i := strings.LastIndex(ref.Name, "-")
+	if i < 0 {
+		return "", false
+	}
+	d, ok := replicaSetNames[ref.Namespace+"/"+ref.Name[:i]]
+	return d, ok
+}
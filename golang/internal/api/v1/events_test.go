@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/config"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func eventsAPI() *API {
+	controller := func(kind, name string, uid types.UID) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{Kind: kind, Name: name, UID: uid, Controller: ptr(true)}}
+	}
+	event := func(name, reason string, involved corev1.ObjectReference, ago time.Duration) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         reason,
+			Count:          1,
+			InvolvedObject: involved,
+			LastTimestamp:  metav1.NewTime(time.Now().Add(-ago)),
+		}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr(int32(1))},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default", UID: "api-uid"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr(int32(1))},
+		},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name: "web-5d9c", Namespace: "default", UID: "rs-uid",
+			OwnerReferences: controller("Deployment", "web", "web-uid"),
+		}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: "web-5d9c-abcde", Namespace: "default", UID: "pod-uid",
+			OwnerReferences: controller("ReplicaSet", "web-5d9c", "rs-uid"),
+		}},
+		event("e1", "FailedCreate", corev1.ObjectReference{Kind: "ReplicaSet", Namespace: "default", Name: "web-5d9c", UID: "rs-uid"}, 10*time.Minute),
+		event("e2", "FailedMount", corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web-5d9c-abcde", UID: "pod-uid"}, time.Minute),
+		// pod already replaced, matched by name
+		event("e3", "BackOff", corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web-5d9c-zzzzz", UID: "gone-uid"}, 5*time.Minute),
+		// other deployment
+		event("e4", "FailedScheduling", corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "api-1", UID: "api-pod"}, time.Minute),
+	)
+	return &API{Config: &config.Config{}, K8sClient: &k8s.Client{Clientset: clientset}}
+}
+
+func TestGetDeploymentEvents(t *testing.T) {
+	mux := http.NewServeMux()
+	eventsAPI().Register(mux)
+
+	req, _ := http.NewRequest("GET", "/deployments/default/web/events", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp DeploymentEvents
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	reasons := []string{}
+	for _, e := range resp.Events {
+		reasons = append(reasons, e.Reason)
+	}
+	// most recent first
+	assert.Equal(t, []string{"FailedMount", "BackOff", "FailedCreate"}, reasons)
+
+	req, _ = http.NewRequest("GET", "/deployments/default/missing/events", nil)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestGetDeployments_Events(t *testing.T) {
+	api := eventsAPI()
+
+	req, _ := http.NewRequest("GET", "/deployments?events=true", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(api.getDeployments).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var deps []EnrichedDeployment
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &deps))
+	events := map[string]int{}
+	for _, d := range deps {
+		events[d.Name] = len(d.Events)
+	}
+	assert.Equal(t, map[string]int{"web": 3, "api": 0}, events)
+}
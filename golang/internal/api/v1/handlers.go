@@ -1,80 +1,135 @@
 package v1
 
 import (
+	"fmt"
 	"net/http"
-	"slices"
 
+	"github.com/moemoeq/tyk-sre-app/internal/api/v1/listing"
+	"github.com/moemoeq/tyk-sre-app/internal/health"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// maxFilteredPageReads bounds the API server reads filling one health filtered page,
+// a page may still come back short with a continue token when few items match.
+const maxFilteredPageReads = 10
+
 func (api *API) getDeployments(w http.ResponseWriter, r *http.Request) {
 	detailed := r.URL.Query().Get("detailed") == "true"
-	namespace := r.URL.Query().Get("namespace")
-	labelSelector := r.URL.Query().Get("labelSelector")
-	fieldSelector := r.URL.Query().Get("fieldSelector")
+	namespace, listOptions := listQuery(r)
+
+	params, err := listing.Parse(r, true, listing.SortNamespace, listing.SortName, listing.SortAge, listing.SortHealth)
+	if err != nil {
+		api.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params.Apply(&listOptions)
 
-	listOptions := metav1.ListOptions{
-		LabelSelector: labelSelector,
-		FieldSelector: fieldSelector,
+	// pages are read live, the cache cannot serve continue tokens
+	var deployments []appsv1.Deployment
+	var listMeta metav1.ListMeta
+	if params.Paginated() {
+		// the health filter runs on what the API server returns, keep reading until the page
+		// is full so that only the last page comes back short
+		need := params.Limit
+		for reads := 1; ; reads++ {
+			page, err := api.client(r.Context()).ListDeploymentPage(r.Context(), namespace, listOptions)
+			if err != nil {
+				api.respondError(w, errorStatus(err), err.Error())
+				return
+			}
+			deployments, listMeta = append(deployments, page.Items...), page.ListMeta
+			for _, d := range page.Items {
+				if params.KeepHealth(health.EvaluateDeployment(&d).Healthy) {
+					need--
+				}
+			}
+			if params.Health == "" || need <= 0 || page.Continue == "" || reads >= maxFilteredPageReads {
+				break
+			}
+			listOptions.Continue, listOptions.Limit = page.Continue, need
+		}
+	} else {
+		deployments, err = api.client(r.Context()).ListDeployments(r.Context(), namespace, listOptions)
+		if err != nil {
+			api.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
 	}
 
-	deployments, err := api.K8sClient.ListDeployments(r.Context(), namespace, listOptions)
+	// the autoscaler only adds detail, deployments are still served without it
+	hpas, err := api.client(r.Context()).ListHorizontalPodAutoscalers(r.Context(), namespace, metav1.ListOptions{})
 	if err != nil {
-		api.respondError(w, http.StatusInternalServerError, err.Error())
-		return
+		fmt.Println("failed to list hpas for deployments", err)
 	}
 
-	response := make([]any, 0, len(deployments))
+	response := make([]EnrichedDeployment, 0, len(deployments))
 	for _, d := range deployments {
-		isHealthy := false
-		desired := int32(0)
-		if d.Spec.Replicas != nil {
-			desired = *d.Spec.Replicas
+		enrichment := enrichDeployment(&d, hpas, detailed)
+		if params.KeepHealth(enrichment.Health) {
+			response = append(response, enrichment)
 		}
+	}
 
-		checkCondition := func(t appsv1.DeploymentConditionType) bool {
-			return slices.ContainsFunc(d.Status.Conditions, func(c appsv1.DeploymentCondition) bool {
-				return c.Type == t && c.Status == "True"
-			})
+	if r.URL.Query().Get("events") == "true" {
+		events, err := api.deploymentEvents(r.Context(), namespace, deployments, eventTypeQuery(r))
+		if err != nil {
+			api.respondError(w, http.StatusInternalServerError, err.Error())
+			return
 		}
-		// Health Logic:
-		// 1. ReadyReplicas must match Desired Replicas
-		// 2. UpdatedReplicas must match Desired Replicas (Rolling update finished)
-		// 3. UnavailableReplicas must be 0
-		// 4. Status.conditions type=Progressing must be True
-		// 5. Status.conditions type=Available must be True
-
-		if d.Status.ReadyReplicas == desired &&
-			d.Status.UpdatedReplicas == desired &&
-			d.Status.UnavailableReplicas == 0 &&
-			checkCondition(appsv1.DeploymentAvailable) &&
-			checkCondition(appsv1.DeploymentProgressing) {
-			isHealthy = true
+		for i := range response {
+			response[i].Events = events[response[i].UID]
 		}
+	}
 
-		enrichment := EnrichedDeployment{
-			TypeMeta:   d.TypeMeta,
-			ObjectMeta: d.ObjectMeta,
-			Status:     d.Status,
-			Health:     isHealthy,
-		}
+	listing.SortItems(response, params,
+		func(d EnrichedDeployment) metav1.Object { return &d.ObjectMeta },
+		func(d EnrichedDeployment) bool { return d.Health },
+	)
 
-		if detailed {
-			enrichment.Spec = &d.Spec
-		} else {
-			// Filter noisy metadata if not detailed
-			enrichment.ManagedFields = nil
-		}
+	if params.Envelope {
+		api.respondJSON(w, http.StatusOK, listing.NewEnvelope(response, params, len(deployments), listMeta))
+		return
+	}
+	api.respondJSON(w, http.StatusOK, response)
+}
+
+// enrichDeployment evaluates health, against the autoscaler too when one of hpas targets d.
+func enrichDeployment(d *appsv1.Deployment, hpas []autoscalingv2.HorizontalPodAutoscaler, detailed bool) EnrichedDeployment {
+	result, hpaStatus := health.EvaluateDeploymentAutoscaled(d, hpas)
 
-		response = append(response, enrichment)
+	enrichment := EnrichedDeployment{
+		TypeMeta:        d.TypeMeta,
+		ObjectMeta:      d.ObjectMeta,
+		Status:          d.Status,
+		Health:          result.Healthy,
+		Degraded:        result.Degraded,
+		HealthReasons:   result.Reasons,
+		DegradedReasons: result.DegradedReasons,
+		HPA:             hpaStatus,
 	}
 
-	api.respondJSON(w, http.StatusOK, response)
+	if detailed {
+		enrichment.Spec = &d.Spec
+	} else {
+		// Filter noisy metadata if not detailed
+		enrichment.ManagedFields = nil
+	}
+
+	return enrichment
+}
+
+// listQuery reads the common namespace and selector filters.
+func listQuery(r *http.Request) (string, metav1.ListOptions) {
+	return r.URL.Query().Get("namespace"), metav1.ListOptions{
+		LabelSelector: r.URL.Query().Get("labelSelector"),
+		FieldSelector: r.URL.Query().Get("fieldSelector"),
+	}
 }
 
 func (api *API) checkK8sReachability(w http.ResponseWriter, r *http.Request) {
-	status := api.K8sClient.CheckConnectivity(r.Context())
+	status := api.client(r.Context()).CheckConnectivity(r.Context())
 
 	httpStatus := http.StatusOK
 	if !status.Status {
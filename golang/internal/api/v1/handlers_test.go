@@ -2,17 +2,27 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/moemoeq/tyk-sre-app/internal/api/v1/listing"
+	"github.com/moemoeq/tyk-sre-app/internal/config"
+	"github.com/moemoeq/tyk-sre-app/internal/health"
 	"github.com/moemoeq/tyk-sre-app/internal/k8s"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/version"
 	disco "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/kubernetes/fake"
+	testing2 "k8s.io/client-go/testing"
 )
 
 func TestGetDeployments_Summary(t *testing.T) {
@@ -122,6 +132,47 @@ func TestGetDeployments_LabelFilter(t *testing.T) {
 	assert.True(t, deps[0].Health)
 }
 
+func TestGetDeployments_HealthReasons(t *testing.T) {
+	fakeClientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deploy",
+			Namespace: "default",
+		},
+		Status: appsv1.DeploymentStatus{
+			ReadyReplicas:   1,
+			UpdatedReplicas: 3,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: "True"},
+				{Type: appsv1.DeploymentProgressing, Status: "False", Reason: "ProgressDeadlineExceeded", Message: "timed out"},
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr(int32(3)),
+		},
+	})
+	kClient := &k8s.Client{Clientset: fakeClientset}
+	api := &API{K8sClient: kClient}
+
+	req, _ := http.NewRequest("GET", "/deployments", nil)
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(api.getDeployments)
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var deps []EnrichedDeployment
+	err := json.Unmarshal(rr.Body.Bytes(), &deps)
+	assert.NoError(t, err)
+	assert.Len(t, deps, 1)
+	assert.False(t, deps[0].Health)
+	assert.Len(t, deps[0].HealthReasons, 2)
+	assert.Equal(t, health.CheckReadyReplicas, deps[0].HealthReasons[0].Check)
+	assert.Equal(t, "1", deps[0].HealthReasons[0].Observed)
+	assert.Equal(t, "3", deps[0].HealthReasons[0].Expected)
+	assert.Equal(t, health.CheckProgressing, deps[0].HealthReasons[1].Check)
+	assert.Equal(t, "timed out", deps[0].HealthReasons[1].Message)
+}
+
 func TestCheckK8sHealth(t *testing.T) {
 	fakeClientset := fake.NewSimpleClientset()
 	// Explicitly set empty version to trigger discovery failure
@@ -145,3 +196,177 @@ func TestCheckK8sHealth(t *testing.T) {
 func ptr[T any](v T) *T {
 	return &v
 }
+
+func TestGetDeployments_HealthFilterAndEnvelope(t *testing.T) {
+	healthy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "healthy", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr(int32(1))},
+		Status: appsv1.DeploymentStatus{
+			ReadyReplicas:   1,
+			UpdatedReplicas: 1,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: "True"},
+				{Type: appsv1.DeploymentProgressing, Status: "True"},
+			},
+		},
+	}
+	broken := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "broken", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr(int32(1))},
+	}
+	api := &API{Config: &config.Config{}, K8sClient: &k8s.Client{Clientset: fake.NewSimpleClientset(healthy, broken)}}
+
+	req, _ := http.NewRequest("GET", "/deployments?health=unhealthy&limit=10", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(api.getDeployments).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var env listing.Envelope[EnrichedDeployment]
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &env))
+	assert.Equal(t, 1, env.Count)
+	assert.Equal(t, "broken", env.Items[0].Name)
+	// total counts the items after the health filter
+	if assert.NotNil(t, env.Total) {
+		assert.Equal(t, int64(1), *env.Total)
+	}
+
+	req, _ = http.NewRequest("GET", "/deployments?sort=size", nil)
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(api.getDeployments).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestGetDeployments_HealthFilterFillsPage(t *testing.T) {
+	deployment := func(name string, ready int32) appsv1.Deployment {
+		return appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr(int32(1))},
+			Status: appsv1.DeploymentStatus{
+				ReadyReplicas:   ready,
+				UpdatedReplicas: 1,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: "True"},
+					{Type: appsv1.DeploymentProgressing, Status: "True"},
+				},
+			},
+		}
+	}
+	// the fake ignores limit and continue, serve the API server pages in order
+	pages := []appsv1.DeploymentList{
+		{Items: []appsv1.Deployment{deployment("a", 1), deployment("b", 1)}, ListMeta: metav1.ListMeta{Continue: "c1"}},
+		{Items: []appsv1.Deployment{deployment("c", 0)}, ListMeta: metav1.ListMeta{Continue: "c2"}},
+		{Items: []appsv1.Deployment{deployment("d", 0)}},
+	}
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "deployments", func(action testing2.Action) (bool, runtime.Object, error) {
+		page := pages[0]
+		pages = pages[1:]
+		return true, &page, nil
+	})
+	api := &API{Config: &config.Config{}, K8sClient: &k8s.Client{Clientset: clientset}}
+
+	req, _ := http.NewRequest("GET", "/deployments?health=unhealthy&limit=2", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(api.getDeployments).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var env listing.Envelope[EnrichedDeployment]
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &env))
+	assert.Equal(t, 2, env.Count)
+	assert.Empty(t, pages, "read on until the page was full")
+	assert.Empty(t, env.Continue)
+	if assert.NotNil(t, env.Total) {
+		assert.Equal(t, int64(2), *env.Total)
+	}
+}
+
+func TestGetDeployments_HPA(t *testing.T) {
+	target := int32(80)
+	utilization := int32(97)
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr(int32(5))},
+			Status: appsv1.DeploymentStatus{
+				ReadyReplicas:   5,
+				UpdatedReplicas: 5,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: "True"},
+					{Type: appsv1.DeploymentProgressing, Status: "True"},
+				},
+			},
+		},
+		// same name in another namespace must not match
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "other"}},
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+				MaxReplicas:    5,
+				Metrics: []autoscalingv2.MetricSpec{{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{
+						Name:   corev1.ResourceCPU,
+						Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: &target},
+					},
+				}},
+			},
+			Status: autoscalingv2.HorizontalPodAutoscalerStatus{
+				CurrentReplicas: 5,
+				DesiredReplicas: 5,
+				CurrentMetrics: []autoscalingv2.MetricStatus{{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricStatus{
+						Name:    corev1.ResourceCPU,
+						Current: autoscalingv2.MetricValueStatus{AverageUtilization: &utilization},
+					},
+				}},
+			},
+		},
+	)
+	api := &API{Config: &config.Config{}, K8sClient: &k8s.Client{Clientset: clientset}}
+
+	req, _ := http.NewRequest("GET", "/deployments", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(api.getDeployments).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var deps []EnrichedDeployment
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &deps))
+	if assert.Len(t, deps, 2) {
+		web := deps[0]
+		assert.Equal(t, "default", web.Namespace)
+		assert.True(t, web.Health)
+		assert.True(t, web.Degraded)
+		assert.Empty(t, web.HealthReasons)
+		if assert.Len(t, web.DegradedReasons, 1) {
+			assert.Equal(t, health.CheckScalingLimited, web.DegradedReasons[0].Check)
+		}
+		if assert.NotNil(t, web.HPA) {
+			assert.True(t, web.HPA.ScalingLimited)
+			assert.Equal(t, int32(5), web.HPA.MaxReplicas)
+		}
+		assert.Nil(t, deps[1].HPA)
+	}
+}
+
+func TestGetDeployments_HPAListError(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+	clientset.PrependReactor("list", "horizontalpodautoscalers", func(action testing2.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "autoscaling", Resource: "horizontalpodautoscalers"}, "", errors.New("rbac"))
+	})
+	api := &API{Config: &config.Config{}, K8sClient: &k8s.Client{Clientset: clientset}}
+
+	req, _ := http.NewRequest("GET", "/deployments", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(api.getDeployments).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var deps []EnrichedDeployment
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &deps))
+	if assert.Len(t, deps, 1) {
+		assert.Nil(t, deps[0].HPA)
+	}
+}
@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/moemoeq/tyk-sre-app/internal/rollout"
+)
+
+// RevisionDiff is the pod template diff between two revisions.
+type RevisionDiff struct {
+	From    int64            `json:"from"`
+	To      int64            `json:"to"`
+	Changes []rollout.Change `json:"changes"`
+}
+
+// Lists the rollout history of a deployment from its owned ReplicaSets,
+// or with ?diff=N..M the pod template changes between two revisions.
+func (api *API) getDeploymentHistory(w http.ResponseWriter, r *http.Request) {
+	d, err := api.client(r.Context()).GetDeployment(r.Context(), r.PathValue("namespace"), r.PathValue("name"))
+	if err != nil {
+		api.respondError(w, errorStatus(err), err.Error())
+		return
+	}
+
+	replicaSets, err := api.client(r.Context()).ListOwnedReplicaSets(r.Context(), d)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	diff := r.URL.Query().Get("diff")
+	if diff == "" {
+		api.respondJSON(w, http.StatusOK, rollout.History(replicaSets))
+		return
+	}
+
+	from, to, err := parseRevisionRange(diff)
+	if err != nil {
+		api.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	fromRS := rollout.FindRevision(replicaSets, from)
+	toRS := rollout.FindRevision(replicaSets, to)
+	if fromRS == nil || toRS == nil {
+		api.respondError(w, http.StatusNotFound, fmt.Sprintf("revision %d or %d not found", from, to))
+		return
+	}
+
+	changes, err := rollout.TemplateDiff(fromRS, toRS)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.respondJSON(w, http.StatusOK, RevisionDiff{From: from, To: to, Changes: changes})
+}
+
+// parseRevisionRange parses "N..M".
+func parseRevisionRange(s string) (int64, int64, error) {
+	parts := strings.Split(s, "..")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("diff must be in the form N..M, got %q", s)
+	}
+	from, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid revision %q", parts[0])
+	}
+	to, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid revision %q", parts[1])
+	}
+	return from, to, nil
+}
@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moemoeq/tyk-sre-app/internal/config"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/moemoeq/tyk-sre-app/internal/rollout"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func ownedReplicaSet(name, revision, image string) *appsv1.ReplicaSet {
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			UID:         types.UID("uid-" + name),
+			Labels:      map[string]string{"app": "web"},
+			Annotations: map[string]string{rollout.RevisionAnnotation: revision},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "Deployment", Name: "web", UID: "web-uid", Controller: ptr(true)},
+			},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: image}}},
+			},
+		},
+	}
+}
+
+func TestGetDeploymentHistory(t *testing.T) {
+	fakeClientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid"},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+		},
+		ownedReplicaSet("web-b", "2", "web:1.1"),
+		ownedReplicaSet("web-a", "1", "web:1.0"),
+	)
+	api := &API{Config: &config.Config{}, K8sClient: &k8s.Client{Clientset: fakeClientset}}
+	mux := http.NewServeMux()
+	api.Register(mux)
+
+	req, _ := http.NewRequest("GET", "/deployments/default/web/history", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var history []rollout.Revision
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &history))
+	assert.Len(t, history, 2)
+	assert.Equal(t, "web-a", history[0].ReplicaSet)
+	assert.Equal(t, "web-b", history[1].ReplicaSet)
+
+	// diff
+	req, _ = http.NewRequest("GET", "/deployments/default/web/history?diff=1..2", nil)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var diff RevisionDiff
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &diff))
+	assert.Equal(t, []rollout.Change{
+		{Path: "spec.containers[0].image", Op: rollout.OpChanged, From: "web:1.0", To: "web:1.1"},
+	}, diff.Changes)
+
+	// unknown revision
+	req, _ = http.NewRequest("GET", "/deployments/default/web/history?diff=1..7", nil)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// malformed range
+	req, _ = http.NewRequest("GET", "/deployments/default/web/history?diff=1-2", nil)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,199 @@
+package listing
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Sort keys.
+const (
+	SortNamespace = "namespace"
+	SortName      = "name"
+	SortAge       = "age" // newest first
+	SortHealth    = "health"
+)
+
+// Health filters.
+const (
+	HealthHealthy   = "healthy"
+	HealthUnhealthy = "unhealthy"
+)
+
+// Params are the pagination, sorting and filtering query parameters of list endpoints.
+type Params struct {
+	Limit    int64
+	Continue string // API server continue token
+	Offset   int64  // items returned by previous pages, after the health filter
+	Sort     string
+	Health   string
+	Envelope bool
+}
+
+// Parse reads limit, continue, sort, health and envelope.
+// sorts lists the accepted sort keys; health filtering is rejected unless withHealth is set.
+func Parse(r *http.Request, withHealth bool, sorts ...string) (Params, error) {
+	q := r.URL.Query()
+	p := Params{
+		Sort:     q.Get("sort"),
+		Health:   q.Get("health"),
+		Envelope: q.Get("envelope") == "true" || q.Has("limit") || q.Has("continue"),
+	}
+
+	if l := q.Get("limit"); l != "" {
+		limit, err := strconv.ParseInt(l, 10, 64)
+		if err != nil || limit < 0 {
+			return p, fmt.Errorf("invalid limit %q", l)
+		}
+		p.Limit = limit
+	}
+
+	if c := q.Get("continue"); c != "" {
+		offset, token, err := decodeContinue(c)
+		if err != nil {
+			return p, fmt.Errorf("invalid continue token")
+		}
+		p.Offset, p.Continue = offset, token
+	}
+
+	if p.Sort != "" && !slices.Contains(sorts, p.Sort) {
+		return p, fmt.Errorf("invalid sort %q, expected one of %s", p.Sort, strings.Join(sorts, "|"))
+	}
+
+	switch {
+	case p.Health == "":
+	case !withHealth:
+		return p, fmt.Errorf("health filter is not supported here")
+	case p.Health != HealthHealthy && p.Health != HealthUnhealthy:
+		return p, fmt.Errorf("invalid health %q, expected healthy|unhealthy", p.Health)
+	}
+
+	return p, nil
+}
+
+// Paginated reports whether the list must be read page by page from the API server.
+func (p Params) Paginated() bool {
+	return p.Limit > 0 || p.Continue != ""
+}
+
+// Apply passes limit and continue through to the API server.
+func (p Params) Apply(opts *metav1.ListOptions) {
+	opts.Limit = p.Limit
+	opts.Continue = p.Continue
+}
+
+// KeepHealth reports whether an item of the given health passes the health filter.
+func (p Params) KeepHealth(healthy bool) bool {
+	switch p.Health {
+	case HealthHealthy:
+		return healthy
+	case HealthUnhealthy:
+		return !healthy
+	default:
+		return true
+	}
+}
+
+// SortItems orders items by p.Sort. health may be nil if the kind has no health.
+// Sorting applies within a page only.
+func SortItems[T any](items []T, p Params, object func(T) metav1.Object, health func(T) bool) {
+	less := func(a, b metav1.Object) bool {
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		return a.GetName() < b.GetName()
+	}
+
+	switch p.Sort {
+	case SortNamespace:
+		sort.SliceStable(items, func(i, j int) bool {
+			return less(object(items[i]), object(items[j]))
+		})
+	case SortName:
+		sort.SliceStable(items, func(i, j int) bool {
+			a, b := object(items[i]), object(items[j])
+			if a.GetName() != b.GetName() {
+				return a.GetName() < b.GetName()
+			}
+			return a.GetNamespace() < b.GetNamespace()
+		})
+	case SortAge:
+		sort.SliceStable(items, func(i, j int) bool {
+			a, b := object(items[i]).GetCreationTimestamp(), object(items[j]).GetCreationTimestamp()
+			return b.Before(&a)
+		})
+	case SortHealth:
+		// unhealthy first
+		sort.SliceStable(items, func(i, j int) bool {
+			hi, hj := health(items[i]), health(items[j])
+			if hi != hj {
+				return !hi
+			}
+			return less(object(items[i]), object(items[j]))
+		})
+	}
+}
+
+// Envelope wraps a list response with pagination metadata.
+type Envelope[T any] struct {
+	Items []T `json:"items"`
+	// Count is the number of items in this response, after the health filter
+	Count int `json:"count"`
+	// Total is the number of items matching namespace, selectors and health across all pages,
+	// omitted while unknown: the API server does not report it with a label selector, and
+	// with a health filter it is only known on the last page
+	Total    *int64 `json:"total,omitempty"`
+	Continue string `json:"continue,omitempty"`
+}
+
+// NewEnvelope builds the envelope of a page. pageSize is the item count before filtering.
+func NewEnvelope[T any](items []T, p Params, pageSize int, meta metav1.ListMeta) Envelope[T] {
+	env := Envelope[T]{Items: items, Count: len(items)}
+
+	offset := p.Offset + int64(pageSize)
+	if p.Health != "" {
+		// the remaining count is before the filter
+		offset = p.Offset + int64(len(items))
+		meta.RemainingItemCount = nil
+	}
+	switch {
+	case meta.RemainingItemCount != nil:
+		total := offset + *meta.RemainingItemCount
+		env.Total = &total
+	case meta.Continue == "":
+		// last page
+		env.Total = &offset
+	}
+
+	if meta.Continue != "" {
+		env.Continue = encodeContinue(offset, meta.Continue)
+	}
+	return env
+}
+
+// The continue token returned to clients carries the offset so totals survive across pages.
+func encodeContinue(offset int64, token string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(offset, 10) + ":" + token))
+}
+
+func decodeContinue(s string) (int64, string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, "", err
+	}
+	offset, token, ok := strings.Cut(string(data), ":")
+	if !ok {
+		return 0, "", fmt.Errorf("malformed token")
+	}
+	n, err := strconv.ParseInt(offset, 10, 64)
+	if err != nil {
+		return 0, "", err
+	}
+	return n, token, nil
+}
@@ -0,0 +1,123 @@
+package listing
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParse(t *testing.T) {
+	sorts := []string{SortNamespace, SortName, SortAge, SortHealth}
+
+	tests := []struct {
+		name     string
+		query    string
+		health   bool
+		wantErr  bool
+		envelope bool
+	}{
+		{name: "Empty", query: "", health: true},
+		{name: "Limit enables envelope", query: "limit=10", health: true, envelope: true},
+		{name: "Envelope on request", query: "envelope=true", health: true, envelope: true},
+		{name: "Negative limit", query: "limit=-1", health: true, wantErr: true},
+		{name: "Unknown sort", query: "sort=size", health: true, wantErr: true},
+		{name: "Unknown health", query: "health=degraded", health: true, wantErr: true},
+		{name: "Health unsupported", query: "health=healthy", health: false, wantErr: true},
+		{name: "Malformed continue", query: "continue=not-a-token", health: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/deployments?"+tt.query, nil)
+			p, err := Parse(req, tt.health, sorts...)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.envelope, p.Envelope)
+		})
+	}
+}
+
+func TestContinueTokenCarriesOffset(t *testing.T) {
+	remaining := int64(5)
+	first := NewEnvelope([]string{"a", "b"}, Params{Limit: 2}, 2, metav1.ListMeta{Continue: "k8s-token", RemainingItemCount: &remaining})
+	assert.Equal(t, int64(7), *first.Total)
+	assert.NotEmpty(t, first.Continue)
+
+	req, _ := http.NewRequest("GET", "/deployments?limit=2&continue="+first.Continue, nil)
+	p, err := Parse(req, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "k8s-token", p.Continue)
+	assert.Equal(t, int64(2), p.Offset)
+
+	opts := metav1.ListOptions{}
+	p.Apply(&opts)
+	assert.Equal(t, "k8s-token", opts.Continue)
+	assert.Equal(t, int64(2), opts.Limit)
+
+	// last page, total is known without a remaining count
+	last := NewEnvelope([]string{"c"}, p, 1, metav1.ListMeta{})
+	assert.Equal(t, int64(3), *last.Total)
+	assert.Empty(t, last.Continue)
+
+	// label selectors hide the remaining count
+	unknown := NewEnvelope([]string{"a"}, Params{Limit: 1}, 1, metav1.ListMeta{Continue: "next"})
+	assert.Nil(t, unknown.Total)
+}
+
+func TestEnvelopeWithHealthFilter(t *testing.T) {
+	remaining := int64(40)
+	first := NewEnvelope([]string{"a"}, Params{Limit: 10, Health: HealthUnhealthy}, 10, metav1.ListMeta{Continue: "next", RemainingItemCount: &remaining})
+	assert.Nil(t, first.Total, "unknown until the last page")
+
+	req, _ := http.NewRequest("GET", "/deployments?health=unhealthy&limit=10&continue="+first.Continue, nil)
+	p, err := Parse(req, true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), p.Offset, "offset counts the items returned")
+
+	last := NewEnvelope([]string{"b", "c"}, p, 10, metav1.ListMeta{})
+	assert.Equal(t, int64(3), *last.Total)
+}
+
+func TestSortItems(t *testing.T) {
+	type item struct {
+		meta    metav1.ObjectMeta
+		healthy bool
+	}
+	now := time.Now()
+	items := func() []item {
+		return []item{
+			{meta: metav1.ObjectMeta{Namespace: "b", Name: "x", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))}, healthy: true},
+			{meta: metav1.ObjectMeta{Namespace: "a", Name: "z", CreationTimestamp: metav1.NewTime(now)}, healthy: false},
+			{meta: metav1.ObjectMeta{Namespace: "a", Name: "y", CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Hour))}, healthy: true},
+		}
+	}
+	object := func(i item) metav1.Object { return &i.meta }
+	healthy := func(i item) bool { return i.healthy }
+	names := func(items []item) []string {
+		out := []string{}
+		for _, i := range items {
+			out = append(out, i.meta.Name)
+		}
+		return out
+	}
+
+	tests := map[string][]string{
+		SortNamespace: {"y", "z", "x"},
+		SortName:      {"x", "y", "z"},
+		SortAge:       {"z", "x", "y"},
+		SortHealth:    {"z", "y", "x"},
+	}
+	for key, want := range tests {
+		t.Run(key, func(t *testing.T) {
+			got := items()
+			SortItems(got, Params{Sort: key}, object, healthy)
+			assert.Equal(t, want, names(got))
+		})
+	}
+}
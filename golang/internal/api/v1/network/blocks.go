@@ -0,0 +1,164 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/blocks"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// blockID identifies the block of a request, the same pair always gets the same ID.
+func blockID(req BlockRequest) string {
+	return hashLabel(req.TargetA.Namespace + "/" + req.TargetA.LabelSelector + "|" + req.TargetB.Namespace + "/" + req.TargetB.LabelSelector)
+}
+
+// blockCreator is the X-Forwarded-User of a trusted proxy, verified, else the created_by of the
+// request as the caller claims it. The proxy wins so that callers cannot record someone else as
+// creator, and the header is ignored from other sources, which could set it to anything.
+func (h *Handler) blockCreator(r *http.Request, req BlockRequest) (string, bool) {
+	if user := r.Header.Get("X-Forwarded-User"); user != "" && h.fromTrustedProxy(r) {
+		return user, true
+	}
+	if req.CreatedBy != "" {
+		return req.CreatedBy, false
+	}
+	return "anonymous", false
+}
+
+// fromTrustedProxy reports whether the request comes from one of the TrustedProxies.
+func (h *Handler) fromTrustedProxy(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range h.TrustedProxies {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			// validated by config.Load
+			continue
+		}
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockExpiry reads the ttl ("1h") or expires_at of a request, nil when the block does not expire.
+func blockExpiry(req BlockRequest, now time.Time) (*time.Time, error) {
+	if req.TTL != "" && req.ExpiresAt != nil {
+		return nil, fmt.Errorf("set either ttl or expires_at, not both")
+	}
+
+	if req.TTL != "" {
+		ttl, err := time.ParseDuration(req.TTL)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid ttl %q, expected a positive duration such as 30m or 1h", req.TTL)
+		}
+		expiresAt := now.Add(ttl).UTC().Truncate(time.Second)
+		return &expiresAt, nil
+	}
+
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) {
+			return nil, fmt.Errorf("expires_at %s is in the past", req.ExpiresAt.Format(time.RFC3339))
+		}
+		expiresAt := req.ExpiresAt.UTC().Truncate(time.Second)
+		return &expiresAt, nil
+	}
+	return nil, nil
+}
+
+// blockPolicies lists the policies of one block, or of every block when id is empty.
+func (h *Handler) blockPolicies(r *http.Request, id string) ([]networkingv1.NetworkPolicy, error) {
+	selector := labels.Set{blocks.LabelBlockID: id}.AsSelector().String()
+	if id == "" {
+		selector = blocks.LabelBlockID
+	}
+	return h.client(r).ListNetworkPolicies(r.Context(), "", metav1.ListOptions{LabelSelector: selector})
+}
+
+// blockIDValue validates the {id} path value, it ends up in a label selector.
+func blockIDValue(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.PathValue("id")
+	if id == "" || strings.Trim(id, "0123456789abcdef") != "" {
+		http.Error(w, fmt.Sprintf("invalid block id %q", id), http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
+
+// ListBlocks handles GET /network/blocks
+func (h *Handler) ListBlocks(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.blockPolicies(r, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(blocks.FromPolicies(policies))
+}
+
+// GetBlock handles GET /network/blocks/{id}
+func (h *Handler) GetBlock(w http.ResponseWriter, r *http.Request) {
+	id, ok := blockIDValue(w, r)
+	if !ok {
+		return
+	}
+
+	policies, err := h.blockPolicies(r, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	found := blocks.FromPolicies(policies)
+	if len(found) == 0 {
+		http.Error(w, fmt.Sprintf("block %s not found", id), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(found[0])
+}
+
+// DeleteBlock handles DELETE /network/blocks/{id}, removing whichever of its policies remain.
+func (h *Handler) DeleteBlock(w http.ResponseWriter, r *http.Request) {
+	id, ok := blockIDValue(w, r)
+	if !ok {
+		return
+	}
+
+	// a stale cache could miss a policy and leave traffic blocked
+	r = r.WithContext(k8s.WithConsistentRead(r.Context()))
+	policies, err := h.blockPolicies(r, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(policies) == 0 {
+		http.Error(w, fmt.Sprintf("block %s not found", id), http.StatusNotFound)
+		return
+	}
+
+	for _, p := range policies {
+		if err := h.client(r).DeleteNetworkPolicy(r.Context(), p.Namespace, p.Name); err != nil && !strings.Contains(err.Error(), "not found") {
+			http.Error(w, fmt.Sprintf("failed to delete policy %s/%s: %v", p.Namespace, p.Name, err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,131 @@
+package network
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/moemoeq/tyk-sre-app/internal/blocks"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func blocksMux(h *Handler) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /network/block", h.BlockWorkloads)
+	mux.HandleFunc("GET /network/blocks", h.ListBlocks)
+	mux.HandleFunc("GET /network/blocks/{id}", h.GetBlock)
+	mux.HandleFunc("DELETE /network/blocks/{id}", h.DeleteBlock)
+	return mux
+}
+
+func serve(mux *http.ServeMux, method, path, body string, header ...string) *httptest.ResponseRecorder {
+	// from 192.0.2.1
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestBlocks(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		// not a block
+		&networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "default-deny", Namespace: "ns-a"}},
+	)
+	mux := blocksMux(&Handler{K8sClient: &k8s.Client{Clientset: clientset}, TrustedProxies: []string{"192.0.2.0/24"}})
+
+	rr := serve(mux, "POST", "/network/block",
+		`{"target_a": {"namespace": "ns-a", "label_selector": "app=foo"}, "target_b": {"namespace": "ns-b", "label_selector": "app=bar"}, "reason": "INC-42", "created_by": "mallory"}`,
+		"X-Forwarded-User", "alice")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var created map[string]string
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	id := created["id"]
+	require.NotEmpty(t, id)
+
+	rr = serve(mux, "GET", "/network/blocks", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var found []blocks.Block
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &found))
+	require.Len(t, found, 1)
+
+	b := found[0]
+	assert.Equal(t, id, b.ID)
+	assert.Equal(t, WorkloadTarget{Namespace: "ns-a", LabelSelector: "app=foo"}, b.TargetA)
+	assert.Equal(t, WorkloadTarget{Namespace: "ns-b", LabelSelector: "app=bar"}, b.TargetB)
+	assert.Equal(t, DirectionIngress, b.Direction)
+	assert.Equal(t, "alice", b.CreatedBy, "the proxy user wins over created_by")
+	assert.True(t, b.CreatedByVerified)
+	assert.Equal(t, "INC-42", b.Reason)
+	assert.False(t, b.CreatedAt.IsZero())
+	assert.True(t, b.Complete)
+	assert.Equal(t, []blocks.PolicyRef{
+		{Namespace: "ns-a", Name: "block-" + id + "-a"},
+		{Namespace: "ns-b", Name: "block-" + id + "-b"},
+	}, b.Policies)
+
+	rr = serve(mux, "GET", "/network/blocks/"+id, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var got blocks.Block
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	assert.Equal(t, b, got)
+
+	// one side removed by hand
+	require.NoError(t, clientset.NetworkingV1().NetworkPolicies("ns-a").Delete(t.Context(), b.Policies[0].Name, metav1.DeleteOptions{}))
+	rr = serve(mux, "GET", "/network/blocks/"+id, "")
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	assert.False(t, got.Complete)
+
+	rr = serve(mux, "DELETE", "/network/blocks/"+id, "")
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	policies, err := clientset.NetworkingV1().NetworkPolicies("").List(t.Context(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, policies.Items, 1)
+	assert.Equal(t, "default-deny", policies.Items[0].Name)
+
+	assert.Equal(t, http.StatusNotFound, serve(mux, "GET", "/network/blocks/"+id, "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(mux, "DELETE", "/network/blocks/"+id, "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(mux, "GET", "/network/blocks/app%3Dfoo", "").Code)
+}
+
+func TestBlockCreator(t *testing.T) {
+	h := &Handler{TrustedProxies: []string{"10.0.0.0/8", "fd00::/8"}}
+	tests := []struct {
+		remoteAddr, header, createdBy, want string
+		verified                            bool
+	}{
+		{"10.1.2.3:4321", "alice", "mallory", "alice", true},
+		{"[fd00::1]:4321", "alice", "", "alice", true},
+		// the header of an untrusted source is ignored
+		{"192.0.2.1:4321", "alice", "mallory", "mallory", false},
+		{"192.0.2.1:4321", "alice", "", "anonymous", false},
+		{"10.1.2.3:4321", "", "bob", "bob", false},
+		{"10.1.2.3:4321", "", "", "anonymous", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/network/block", nil)
+		r.RemoteAddr = tt.remoteAddr
+		if tt.header != "" {
+			r.Header.Set("X-Forwarded-User", tt.header)
+		}
+		creator, verified := h.blockCreator(r, BlockRequest{CreatedBy: tt.createdBy})
+		assert.Equal(t, tt.want, creator)
+		assert.Equal(t, tt.verified, verified)
+	}
+
+	// without trusted proxies the header is never honoured
+	r := httptest.NewRequest("POST", "/network/block", nil)
+	r.Header.Set("X-Forwarded-User", "alice")
+	creator, verified := (&Handler{}).blockCreator(r, BlockRequest{CreatedBy: "bob"})
+	assert.Equal(t, "bob", creator)
+	assert.False(t, verified)
+}
@@ -0,0 +1,160 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/moemoeq/tyk-sre-app/internal/blocks"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// ?dryRun= modes of POST /network/block
+const (
+	// Policies generated locally, nothing is sent to the API server
+	DryRunClient = "true"
+	// Policies validated and defaulted by the API server with dryRun=All, nothing is persisted
+	DryRunServer = "server"
+)
+
+// TargetPreview lists the pods a target selector currently matches.
+type TargetPreview struct {
+	WorkloadTarget
+	Pods  []string `json:"pods"`
+	Count int      `json:"count"`
+}
+
+// BlockPreview is what a block request would create, returned by ?dryRun=.
+type BlockPreview struct {
+	DryRun   string                       `json:"dry_run"`
+	Block    blocks.Block                 `json:"block"`
+	Policies []networkingv1.NetworkPolicy `json:"policies"`
+	TargetA  TargetPreview                `json:"target_a"`
+	TargetB  TargetPreview                `json:"target_b"`
+	Warnings []string                     `json:"warnings"`
+}
+
+// dryRunQuery reads ?dryRun=, empty when the request must be applied.
+func dryRunQuery(r *http.Request) (string, error) {
+	switch mode := r.URL.Query().Get("dryRun"); mode {
+	case "", "false":
+		return "", nil
+	case DryRunClient, DryRunServer:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid dryRun %q, expected true or server", mode)
+	}
+}
+
+// previewBlock responds with the policies of a block and the pods its targets match,
+// in YAML with ?format=yaml or an Accept header asking for it.
+func (h *Handler) previewBlock(w http.ResponseWriter, r *http.Request, mode string, block blocks.Block, policies ...*networkingv1.NetworkPolicy) {
+	preview := BlockPreview{DryRun: mode, Block: block, Warnings: []string{}}
+
+	for _, p := range policies {
+		if mode == DryRunServer {
+			created, err := h.client(r).DryRunCreateNetworkPolicy(r.Context(), p)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("server dry-run of policy %s/%s: %v", p.Namespace, p.Name, err), dryRunErrorStatus(err))
+				return
+			}
+			p = created
+		}
+
+		manifest := *p.DeepCopy()
+		manifest.TypeMeta = metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy"}
+		manifest.ManagedFields = nil
+		preview.Policies = append(preview.Policies, manifest)
+		preview.Block.Policies = append(preview.Block.Policies, blocks.PolicyRef{Namespace: p.Namespace, Name: p.Name})
+	}
+
+	var err error
+	if preview.TargetA, err = h.previewTarget(r, block.TargetA); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if preview.TargetB, err = h.previewTarget(r, block.TargetB); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	preview.Warnings = append(preview.Warnings, h.targetWarnings("target_a", preview.TargetA)...)
+	preview.Warnings = append(preview.Warnings, h.targetWarnings("target_b", preview.TargetB)...)
+	if overlap := overlappingPods(preview.TargetA, preview.TargetB); len(overlap) > 0 {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("target_a and target_b both match %d pods (%s), they would be cut off from themselves", len(overlap), strings.Join(overlap, ", ")))
+	}
+
+	if r.URL.Query().Get("format") == "yaml" || strings.Contains(r.Header.Get("Accept"), "yaml") {
+		body, err := yaml.Marshal(preview)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(preview)
+}
+
+func (h *Handler) previewTarget(r *http.Request, target WorkloadTarget) (TargetPreview, error) {
+	pods, err := h.client(r).ListPods(r.Context(), target.Namespace, metav1.ListOptions{LabelSelector: target.LabelSelector})
+	if err != nil {
+		return TargetPreview{}, fmt.Errorf("list pods of %s: %w", target.Namespace, err)
+	}
+
+	preview := TargetPreview{WorkloadTarget: target, Pods: make([]string, 0, len(pods)), Count: len(pods)}
+	for _, p := range pods {
+		preview.Pods = append(preview.Pods, p.Name)
+	}
+	return preview, nil
+}
+
+// targetWarnings flags selectors matching nothing, likely a typo, or more pods than PodWarnThreshold.
+func (h *Handler) targetWarnings(name string, t TargetPreview) []string {
+	switch {
+	case t.Count == 0:
+		return []string{fmt.Sprintf("%s selector %q matches no pods in namespace %s", name, t.LabelSelector, t.Namespace)}
+	case h.PodWarnThreshold > 0 && t.Count > h.PodWarnThreshold:
+		return []string{fmt.Sprintf("%s selector %q matches %d pods in namespace %s, more than the threshold of %d", name, t.LabelSelector, t.Count, t.Namespace, h.PodWarnThreshold)}
+	}
+	return nil
+}
+
+func overlappingPods(a, b TargetPreview) []string {
+	if a.Namespace != b.Namespace {
+		return nil
+	}
+	inA := map[string]bool{}
+	for _, p := range a.Pods {
+		inA[p] = true
+	}
+	var overlap []string
+	for _, p := range b.Pods {
+		if inA[p] {
+			overlap = append(overlap, p)
+		}
+	}
+	return overlap
+}
+
+func dryRunErrorStatus(err error) int {
+	switch {
+	case apierrors.IsAlreadyExists(err), apierrors.IsConflict(err):
+		return http.StatusConflict
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		return http.StatusBadRequest
+	case apierrors.IsForbidden(err):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,103 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/moemoeq/tyk-sre-app/internal/blocks"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	testing2 "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
+)
+
+const dryRunBody = `{"target_a": {"namespace": "ns-a", "label_selector": "app=foo"}, "target_b": {"namespace": "ns-b", "label_selector": "app=bar"}, "direction": "both"}`
+
+func dryRunClientset() *fake.Clientset {
+	var objects []runtime.Object
+	for i := 0; i < 3; i++ {
+		objects = append(objects, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("foo-%d", i), Namespace: "ns-a", Labels: map[string]string{"app": "foo"},
+		}})
+	}
+	return fake.NewSimpleClientset(objects...)
+}
+
+func TestBlockWorkloads_DryRun(t *testing.T) {
+	clientset := dryRunClientset()
+	mux := blocksMux(&Handler{K8sClient: &k8s.Client{Clientset: clientset}, PodCIDRs: []string{"10.244.0.0/16"}, PodWarnThreshold: 2})
+
+	rr := serve(mux, "POST", "/network/block?dryRun=true", dryRunBody)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var preview BlockPreview
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &preview))
+	assert.Equal(t, DryRunClient, preview.DryRun)
+	require.Len(t, preview.Policies, 2)
+	for _, p := range preview.Policies {
+		assert.Equal(t, "NetworkPolicy", p.Kind)
+		assert.Equal(t, preview.Block.ID, p.Labels[blocks.LabelBlockID])
+		assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, p.Spec.PolicyTypes)
+	}
+	assert.Equal(t, 3, preview.TargetA.Count)
+	assert.ElementsMatch(t, []string{"foo-0", "foo-1", "foo-2"}, preview.TargetA.Pods)
+	assert.Equal(t, 0, preview.TargetB.Count)
+	assert.Equal(t, []string{
+		`target_a selector "app=foo" matches 3 pods in namespace ns-a, more than the threshold of 2`,
+		`target_b selector "app=bar" matches no pods in namespace ns-b`,
+	}, preview.Warnings)
+
+	// nothing applied
+	for _, a := range clientset.Actions() {
+		assert.NotEqual(t, "create", a.GetVerb())
+	}
+
+	rr = serve(mux, "POST", "/network/block?dryRun=true&format=yaml", dryRunBody)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/yaml", rr.Header().Get("Content-Type"))
+	var fromYAML BlockPreview
+	require.NoError(t, yaml.Unmarshal(rr.Body.Bytes(), &fromYAML))
+	assert.Len(t, fromYAML.Policies, 2)
+
+	assert.Equal(t, http.StatusBadRequest, serve(mux, "POST", "/network/block?dryRun=maybe", dryRunBody).Code)
+}
+
+func TestBlockWorkloads_ServerDryRun(t *testing.T) {
+	clientset := dryRunClientset()
+	var exists bool
+	// the fake ignores CreateOptions.DryRun, answer like the API server without persisting
+	clientset.PrependReactor("create", "networkpolicies", func(action testing2.Action) (bool, runtime.Object, error) {
+		policy := action.(testing2.CreateAction).GetObject().(*networkingv1.NetworkPolicy).DeepCopy()
+		if exists {
+			return true, nil, apierrors.NewAlreadyExists(schema.GroupResource{Group: "networking.k8s.io", Resource: "networkpolicies"}, policy.Name)
+		}
+		policy.UID = "dry-run-uid"
+		return true, policy, nil
+	})
+	mux := blocksMux(&Handler{K8sClient: &k8s.Client{Clientset: clientset}, PodCIDRs: []string{"10.244.0.0/16"}})
+
+	rr := serve(mux, "POST", "/network/block?dryRun=server", dryRunBody)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var preview BlockPreview
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &preview))
+	assert.Equal(t, DryRunServer, preview.DryRun)
+	require.Len(t, preview.Policies, 2)
+	assert.Equal(t, "dry-run-uid", string(preview.Policies[0].UID))
+
+	policies, err := clientset.NetworkingV1().NetworkPolicies("").List(t.Context(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, policies.Items)
+
+	exists = true
+	assert.Equal(t, http.StatusConflict, serve(mux, "POST", "/network/block?dryRun=server", dryRunBody).Code)
+}
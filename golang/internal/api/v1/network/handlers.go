@@ -2,34 +2,93 @@ package network
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/hashstructure/v2"
+	"github.com/moemoeq/tyk-sre-app/internal/api/v1/listing"
+	"github.com/moemoeq/tyk-sre-app/internal/blocks"
 	"github.com/moemoeq/tyk-sre-app/internal/k8s"
 	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 )
 
 type Handler struct {
 	K8sClient *k8s.Client
+	// Pod CIDRs of the cluster, egress blocks allow the addresses outside them
+	PodCIDRs []string
+	// Block previews warn when a target matches more pods, 0 disables the warning
+	PodWarnThreshold int
+	// CIDRs of the proxies whose X-Forwarded-User header is trusted
+	TrustedProxies []string
+}
+
+// client returns the cluster client selected in the request context, K8sClient otherwise.
+func (h *Handler) client(r *http.Request) *k8s.Client {
+	if c, ok := k8s.ClientFrom(r.Context()); ok {
+		return c
+	}
+	return h.K8sClient
 }
 
 // Helpers
-func parseLabelSelector(s string) map[string]string {
-	labels := make(map[string]string)
-	if s == "" {
-		return labels
-	}
-	parts := strings.Split(s, ",")
-	for _, part := range parts {
-		kv := strings.Split(strings.TrimSpace(part), "=")
-		if len(kv) == 2 {
-			labels[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+
+// parseLabelSelector parses a Kubernetes label selector ("app=a,tier in (web,api),!canary").
+// Selectors a NetworkPolicy cannot express (gt, lt) and empty ones, which would match every pod, are rejected.
+func parseLabelSelector(s string) (*metav1.LabelSelector, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, fmt.Errorf("label selector is required")
+	}
+
+	reqs, err := labels.ParseToRequirements(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector %q: %v", s, err)
+	}
+
+	selector := &metav1.LabelSelector{}
+	for _, req := range reqs {
+		values := req.Values().List()
+		var op metav1.LabelSelectorOperator
+		switch req.Operator() {
+		case selection.Equals, selection.DoubleEquals:
+			// a repeated key ("app=a,app=b") must still match both terms
+			if _, dup := selector.MatchLabels[req.Key()]; !dup {
+				if selector.MatchLabels == nil {
+					selector.MatchLabels = map[string]string{}
+				}
+				selector.MatchLabels[req.Key()] = values[0]
+				continue
+			}
+			op = metav1.LabelSelectorOpIn
+		case selection.In:
+			op = metav1.LabelSelectorOpIn
+		case selection.NotEquals, selection.NotIn:
+			op = metav1.LabelSelectorOpNotIn
+		case selection.Exists:
+			op = metav1.LabelSelectorOpExists
+		case selection.DoesNotExist:
+			op = metav1.LabelSelectorOpDoesNotExist
+		default:
+			return nil, fmt.Errorf("invalid label selector %q: operator %q is not supported by network policies", s, req.Operator())
+		}
+		if op == metav1.LabelSelectorOpExists || op == metav1.LabelSelectorOpDoesNotExist {
+			values = nil
 		}
+		selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      req.Key(),
+			Operator: op,
+			Values:   values,
+		})
 	}
-	return labels
+	return selector, nil
 }
 
 func hashLabel(s string) string {
@@ -41,28 +100,42 @@ func hashLabel(s string) string {
 	return fmt.Sprintf("%x", hash)
 }
 
-func generatePolicyName(namespace, labelSelector string) string {
-	return "block-from-" + namespace + "-" + hashLabel(labelSelector)
+// blockPolicyName names the policy of one side of a block, "a" in the namespace of target_a and "b"
+// in that of target_b. Two blocks never share a name, as it derives from the block ID.
+func blockPolicyName(id, side string) string {
+	return "block-" + id + "-" + side
 }
 
-func convertToNotIn(labels map[string]string) []metav1.LabelSelectorRequirement {
+// complementRequirements returns, for each term of the selector, the requirement matching
+// the pods that fail it. A pod is outside the selector when it fails any one term.
+func complementRequirements(selector *metav1.LabelSelector) []metav1.LabelSelectorRequirement {
 	var reqs []metav1.LabelSelectorRequirement
-	for k, v := range labels {
+
+	keys := make([]string, 0, len(selector.MatchLabels))
+	for k := range selector.MatchLabels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		// NotIn also matches pods without the key
 		reqs = append(reqs, metav1.LabelSelectorRequirement{
 			Key:      k,
 			Operator: metav1.LabelSelectorOpNotIn,
-			Values:   []string{v},
+			Values:   []string{selector.MatchLabels[k]},
 		})
 	}
-	return reqs
-}
 
-func convertToDoesNotExist(labels map[string]string) []metav1.LabelSelectorRequirement {
-	var reqs []metav1.LabelSelectorRequirement
-	for k := range labels {
+	complements := map[metav1.LabelSelectorOperator]metav1.LabelSelectorOperator{
+		metav1.LabelSelectorOpIn:           metav1.LabelSelectorOpNotIn,
+		metav1.LabelSelectorOpNotIn:        metav1.LabelSelectorOpIn,
+		metav1.LabelSelectorOpExists:       metav1.LabelSelectorOpDoesNotExist,
+		metav1.LabelSelectorOpDoesNotExist: metav1.LabelSelectorOpExists,
+	}
+	for _, req := range selector.MatchExpressions {
 		reqs = append(reqs, metav1.LabelSelectorRequirement{
-			Key:      k,
-			Operator: metav1.LabelSelectorOpDoesNotExist,
+			Key:      req.Key,
+			Operator: complements[req.Operator],
+			Values:   req.Values,
 		})
 	}
 	return reqs
@@ -81,11 +154,30 @@ func (h *Handler) ListPolicies(w http.ResponseWriter, r *http.Request) {
 		LabelSelector: r.URL.Query().Get("labelSelector"),
 	}
 
-	policies, err := h.K8sClient.ListNetworkPolicies(r.Context(), namespace, listOptions)
+	// policies have no health, so neither health sort nor filter
+	params, err := listing.Parse(r, false, listing.SortNamespace, listing.SortName, listing.SortAge)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	params.Apply(&listOptions)
+
+	var policies []networkingv1.NetworkPolicy
+	var listMeta metav1.ListMeta
+	if params.Paginated() {
+		page, err := h.client(r).ListNetworkPolicyPage(r.Context(), namespace, listOptions)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		policies, listMeta = page.Items, page.ListMeta
+	} else {
+		policies, err = h.client(r).ListNetworkPolicies(r.Context(), namespace, listOptions)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
 
 	if !detailed {
 		for i := range policies {
@@ -93,53 +185,133 @@ func (h *Handler) ListPolicies(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	listing.SortItems(policies, params, func(p networkingv1.NetworkPolicy) metav1.Object { return &p.ObjectMeta }, nil)
+
 	w.Header().Set("Content-Type", "application/json")
+	if params.Envelope {
+		json.NewEncoder(w).Encode(listing.NewEnvelope(policies, params, len(policies), listMeta))
+		return
+	}
 	json.NewEncoder(w).Encode(policies)
 }
 
-type WorkloadTarget struct {
-	Namespace     string `json:"namespace"`
-	LabelSelector string `json:"label_selector"`
-}
+// WorkloadTarget is one side of a block request.
+type WorkloadTarget = blocks.WorkloadTarget
+
+// Traffic directions a block applies to, on both targets.
+const (
+	DirectionIngress = "ingress"
+	DirectionEgress  = "egress"
+	DirectionBoth    = "both"
+)
 
 type BlockRequest struct {
 	TargetA WorkloadTarget `json:"target_a"`
 	TargetB WorkloadTarget `json:"target_b"`
+	// ingress (default), egress or both
+	Direction string `json:"direction,omitempty"`
+	// Recorded on the policies, see Block. CreatedBy is ignored when the X-Forwarded-User header is set
+	CreatedBy string `json:"created_by,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	// Optional expiry, either a duration from now ("1h") or a time; expired blocks are removed by the Reaper
+	TTL       string     `json:"ttl,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 // Creates NetworkPolicies to block traffic between two workloads.
 // Policies are created on both workloads.
 // If the operation fails, attempt to rollback by deleting the created policies. (keep pair)
 
+// With ?dryRun=true or ?dryRun=server nothing is applied, see BlockPreview.
 func (h *Handler) BlockWorkloads(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := dryRunQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var req BlockRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	policyA := h.generateBlockPolicy(req.TargetA, req.TargetB)
-	if _, err := h.K8sClient.CreateNetworkPolicy(r.Context(), policyA); err != nil {
-		http.Error(w, "failed to create policy A: "+err.Error(), http.StatusInternalServerError)
+	direction := req.Direction
+	if direction == "" {
+		direction = DirectionIngress
+	}
+	if direction != DirectionIngress && direction != DirectionEgress && direction != DirectionBoth {
+		http.Error(w, fmt.Sprintf("invalid direction %q, expected ingress, egress or both", req.Direction), http.StatusBadRequest)
+		return
+	}
+	if direction != DirectionIngress && len(h.PodCIDRs) == 0 {
+		http.Error(w, errEgressWithoutPodCIDRs.Error(), http.StatusBadRequest)
+		return
+	}
+
+	creator, verified := h.blockCreator(r, req)
+	id := blockID(req)
+	policyA, err := h.generateBlockPolicy(blockPolicyName(id, "a"), req.TargetA, req.TargetB, direction)
+	if err != nil {
+		http.Error(w, "target_a/target_b: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	policyB, err := h.generateBlockPolicy(blockPolicyName(id, "b"), req.TargetB, req.TargetA, direction)
+	if err != nil {
+		http.Error(w, "target_a/target_b: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	expiresAt, err := blockExpiry(req, now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	block := blocks.Block{
+		ID:                id,
+		TargetA:           req.TargetA,
+		TargetB:           req.TargetB,
+		Direction:         direction,
+		CreatedBy:         creator,
+		CreatedByVerified: verified,
+		Reason:            req.Reason,
+		CreatedAt:         now.UTC().Truncate(time.Second),
+		ExpiresAt:         expiresAt,
+	}
+	blocks.Label(policyA, block)
+	blocks.Label(policyB, block)
+
+	if dryRun != "" {
+		h.previewBlock(w, r, dryRun, block, policyA, policyB)
+		return
+	}
+
+	if _, err := h.client(r).CreateNetworkPolicy(r.Context(), policyA); err != nil {
+		http.Error(w, "failed to create policy A: "+err.Error(), createErrorStatus(err))
 		return
 	}
 
-	policyB := h.generateBlockPolicy(req.TargetB, req.TargetA)
-	if _, err := h.K8sClient.CreateNetworkPolicy(r.Context(), policyB); err != nil {
+	if _, err := h.client(r).CreateNetworkPolicy(r.Context(), policyB); err != nil {
 		// TODO: implement much more elegant rollback
 		// Delete Policy A (rollback)
-		errA := h.K8sClient.DeleteNetworkPolicy(r.Context(), req.TargetA.Namespace, policyA.Name)
+		errA := h.client(r).DeleteNetworkPolicy(r.Context(), req.TargetA.Namespace, policyA.Name)
 		if errA != nil {
 			http.Error(w, "failed to create policy B and failed to rollback policy A(DELETE): "+errA.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		http.Error(w, "failed to create policy B: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "failed to create policy B: "+err.Error(), createErrorStatus(err))
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "blocked"})
+	response := map[string]string{"status": "blocked", "id": block.ID, "direction": direction}
+	if expiresAt != nil {
+		response["expires_at"] = expiresAt.Format(time.RFC3339)
+	}
+	json.NewEncoder(w).Encode(response)
 }
 
 // Deletes the blocking NetworkPolicies.
@@ -151,12 +323,13 @@ func (h *Handler) UnblockWorkloads(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// get policy names
-	policyNameA := generatePolicyName(req.TargetB.Namespace, req.TargetB.LabelSelector)
-	policyNameB := generatePolicyName(req.TargetA.Namespace, req.TargetA.LabelSelector)
+	// get policy names, the same pair as in the block request
+	id := blockID(req)
+	policyNameA := blockPolicyName(id, "a")
+	policyNameB := blockPolicyName(id, "b")
 
 	// Delete Policies
-	if err := h.K8sClient.DeleteNetworkPolicy(r.Context(), req.TargetA.Namespace, policyNameA); err != nil {
+	if err := h.client(r).DeleteNetworkPolicy(r.Context(), req.TargetA.Namespace, policyNameA); err != nil {
 		status := http.StatusInternalServerError
 		if strings.Contains(err.Error(), "not found") {
 			status = http.StatusNotFound
@@ -165,7 +338,7 @@ func (h *Handler) UnblockWorkloads(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// TODO: Decide to implement or not rollback process(do or donot?)
-	if err := h.K8sClient.DeleteNetworkPolicy(r.Context(), req.TargetB.Namespace, policyNameB); err != nil {
+	if err := h.client(r).DeleteNetworkPolicy(r.Context(), req.TargetB.Namespace, policyNameB); err != nil {
 		status := http.StatusInternalServerError
 		if strings.Contains(err.Error(), "not found") {
 			status = http.StatusNotFound
@@ -178,64 +351,113 @@ func (h *Handler) UnblockWorkloads(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "unblocked"})
 }
 
+// createErrorStatus is 409 when the block already exists, 500 otherwise.
+func createErrorStatus(err error) int {
+	if apierrors.IsAlreadyExists(err) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// errEgressWithoutPodCIDRs rejects egress blocks that would also cut every destination outside the pods.
+var errEgressWithoutPodCIDRs = errors.New("egress blocks need NETWORK_POD_CIDRS, else they drop all traffic leaving the cluster pods")
+
 // Helper to generate "Allow All Except" NetworkPolicy
 // WHY? K8S NetworkPolicy doesn't support "deny" policy only works "allow" based
 // so we need to create "Allow All Except" policy
-func (h *Handler) generateBlockPolicy(target, blocked WorkloadTarget) *networkingv1.NetworkPolicy {
+// Egress gets the same peers, so the target can neither receive from nor connect to the blocked pods.
+func (h *Handler) generateBlockPolicy(policyName string, target, blocked WorkloadTarget, direction string) (*networkingv1.NetworkPolicy, error) {
+	if direction != DirectionIngress && len(h.PodCIDRs) == 0 {
+		return nil, errEgressWithoutPodCIDRs
+	}
+
 	// Parse label selectors
-	targetLabels := parseLabelSelector(target.LabelSelector)
-	blockedLabels := parseLabelSelector(blocked.LabelSelector)
+	targetSelector, err := parseLabelSelector(target.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	blockedSelector, err := parseLabelSelector(blocked.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
 
-	policyName := generatePolicyName(blocked.Namespace, blocked.LabelSelector)
+	peers := []networkingv1.NetworkPolicyPeer{
+		// Rule 1: Allow any Namespace NOT equal to blocked.Namespace
+		{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      "kubernetes.io/metadata.name",
+						Operator: metav1.LabelSelectorOpNotIn,
+						Values:   []string{blocked.Namespace},
+					},
+				},
+			},
+		},
+	}
+	// Rule 2: Allow same Namespace (blocked.Namespace) BUT pods failing any blocked term,
+	// one peer per term since peers are ORed and expressions within a selector ANDed
+	for _, req := range complementRequirements(blockedSelector) {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"kubernetes.io/metadata.name": blocked.Namespace},
+			},
+			PodSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{req},
+			},
+		})
+	}
 
-	return &networkingv1.NetworkPolicy{
+	policy := &networkingv1.NetworkPolicy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      policyName,
 			Namespace: target.Namespace,
 		},
 		Spec: networkingv1.NetworkPolicySpec{
-			PodSelector: metav1.LabelSelector{
-				MatchLabels: targetLabels,
-			},
-			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
-			Ingress: []networkingv1.NetworkPolicyIngressRule{
-				{
-					From: []networkingv1.NetworkPolicyPeer{
-						// Rule 1: Allow any Namespace NOT equal to blocked.Namespace
-						{
-							NamespaceSelector: &metav1.LabelSelector{
-								MatchExpressions: []metav1.LabelSelectorRequirement{
-									{
-										Key:      "kubernetes.io/metadata.name",
-										Operator: metav1.LabelSelectorOpNotIn,
-										Values:   []string{blocked.Namespace},
-									},
-								},
-							},
-						},
-						// Rule 2: Allow same Namespace (blocked.Namespace) BUT NOT blocked labels
-						{
-							NamespaceSelector: &metav1.LabelSelector{
-								MatchLabels: map[string]string{"kubernetes.io/metadata.name": blocked.Namespace},
-							},
-							PodSelector: &metav1.LabelSelector{
-								MatchExpressions: convertToNotIn(blockedLabels),
-							},
-						},
-						// Rule 3: Allow same Namespace (blocked.Namespace) BUT DoesNotExist (key missing)
-						{
-							NamespaceSelector: &metav1.LabelSelector{
-								MatchLabels: map[string]string{"kubernetes.io/metadata.name": blocked.Namespace},
-							},
-							PodSelector: &metav1.LabelSelector{
-								MatchExpressions: convertToDoesNotExist(blockedLabels),
-							},
-						},
-					},
-				},
-			},
+			PodSelector: *targetSelector,
 		},
 	}
+
+	if direction == DirectionIngress || direction == DirectionBoth {
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, networkingv1.PolicyTypeIngress)
+		policy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{From: peers}}
+	}
+	if direction == DirectionEgress || direction == DirectionBoth {
+		// Rule 3: Allow addresses outside the cluster, pod selectors only match pods
+		egressPeers := append(append([]networkingv1.NetworkPolicyPeer{}, peers...), externalPeers(h.PodCIDRs)...)
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+		policy.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{{To: egressPeers}}
+	}
+
+	return policy, nil
+}
+
+// externalPeers allows every address outside the pod CIDRs, per IP family.
+// ipBlock must not cover pod IPs, or it would re-allow the blocked pods.
+func externalPeers(podCIDRs []string) []networkingv1.NetworkPolicyPeer {
+	except := map[string][]string{}
+	for _, cidr := range podCIDRs {
+		ip, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			// validated by config.Load
+			continue
+		}
+		all := "0.0.0.0/0"
+		if ip.To4() == nil {
+			all = "::/0"
+		}
+		except[all] = append(except[all], ipNet.String())
+	}
+
+	var peers []networkingv1.NetworkPolicyPeer
+	for _, all := range []string{"0.0.0.0/0", "::/0"} {
+		if len(except[all]) > 0 {
+			peers = append(peers, networkingv1.NetworkPolicyPeer{
+				IPBlock: &networkingv1.IPBlock{CIDR: all, Except: except[all]},
+			})
+		}
+	}
+	return peers
 }
 
 // for manual deletion policy by name or UID.
@@ -247,7 +469,7 @@ func (h *Handler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
 	// Delete by UID
 	// just UID, or (optional) Namespace + UID
 	if uid != "" {
-		if err := h.K8sClient.DeleteNetworkPolicyByUID(r.Context(), namespace, uid); err != nil {
+		if err := h.client(r).DeleteNetworkPolicyByUID(r.Context(), namespace, uid); err != nil {
 			status := http.StatusInternalServerError
 			if strings.Contains(err.Error(), "not found") {
 				status = http.StatusNotFound
@@ -265,7 +487,7 @@ func (h *Handler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.K8sClient.DeleteNetworkPolicy(r.Context(), namespace, name); err != nil {
+	if err := h.client(r).DeleteNetworkPolicy(r.Context(), namespace, name); err != nil {
 		status := http.StatusInternalServerError
 		if strings.Contains(err.Error(), "not found") {
 			status = http.StatusNotFound
@@ -1,11 +1,13 @@
 package network
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/moemoeq/tyk-sre-app/internal/api/v1/listing"
 	"github.com/moemoeq/tyk-sre-app/internal/k8s"
 	"github.com/stretchr/testify/assert"
 	networkingv1 "k8s.io/api/networking/v1"
@@ -135,22 +137,84 @@ func TestBlockWorkloads(t *testing.T) {
 			pol := action.(testing2.CreateAction).GetObject().(*networkingv1.NetworkPolicy)
 			// Basic check
 			if pol.Namespace == "ns-a" {
-				assert.Contains(t, pol.Name, "block-from-ns-b")
+				assert.True(t, strings.HasSuffix(pol.Name, "-a"))
 			} else if pol.Namespace == "ns-b" {
-				assert.Contains(t, pol.Name, "block-from-ns-a")
+				assert.True(t, strings.HasSuffix(pol.Name, "-b"))
 			}
 		}
 	}
 	assert.Equal(t, 2, createActions)
 }
 
+func TestBlockWorkloads_SharedTarget(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	h := &Handler{K8sClient: &k8s.Client{Clientset: clientset}}
+	block := func(selectorA string) int {
+		body := `{"target_a": {"namespace": "ns-a", "label_selector": "` + selectorA + `"}, "target_b": {"namespace": "ns-b", "label_selector": "app=bar"}}`
+		req, err := http.NewRequest("POST", "/api/v1/network/block", strings.NewReader(body))
+		assert.NoError(t, err)
+		rr := httptest.NewRecorder()
+		h.BlockWorkloads(rr, req)
+		return rr.Code
+	}
+
+	// both block app=bar from ns-a, their policies there must not collide
+	assert.Equal(t, http.StatusOK, block("app=foo"))
+	assert.Equal(t, http.StatusOK, block("app=baz"))
+	policies, err := clientset.NetworkingV1().NetworkPolicies("").List(t.Context(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, policies.Items, 4)
+
+	assert.Equal(t, http.StatusConflict, block("app=foo"), "the same block twice")
+}
+
+func TestBlockWorkloads_Direction(t *testing.T) {
+	tests := []struct {
+		direction string
+		status    int
+		types     []networkingv1.PolicyType
+	}{
+		{"", http.StatusOK, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}},
+		{DirectionEgress, http.StatusOK, []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}},
+		{DirectionBoth, http.StatusOK, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}},
+		{"sideways", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.direction, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			h := &Handler{K8sClient: &k8s.Client{Clientset: clientset}, PodCIDRs: []string{"10.244.0.0/16"}}
+
+			body := `{"target_a": {"namespace": "ns-a", "label_selector": "app=foo"}, "target_b": {"namespace": "ns-b", "label_selector": "app=bar"}, "direction": "` + tt.direction + `"}`
+			req, err := http.NewRequest("POST", "/api/v1/network/block", strings.NewReader(body))
+			assert.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			h.BlockWorkloads(rr, req)
+			assert.Equal(t, tt.status, rr.Code)
+
+			policies, err := clientset.NetworkingV1().NetworkPolicies("").List(req.Context(), metav1.ListOptions{})
+			assert.NoError(t, err)
+			if tt.types == nil {
+				assert.Empty(t, policies.Items)
+				return
+			}
+			// both sides
+			assert.Len(t, policies.Items, 2)
+			for _, p := range policies.Items {
+				assert.Equal(t, tt.types, p.Spec.PolicyTypes)
+			}
+		})
+	}
+}
+
 func TestUnblockWorkloads(t *testing.T) {
 	// Calculate expected names
-	hashA := hashLabel("app=foo")
-	hashB := hashLabel("app=bar")
-
-	policyNameA := "block-from-ns-b-" + hashB
-	policyNameB := "block-from-ns-a-" + hashA
+	id := blockID(BlockRequest{
+		TargetA: WorkloadTarget{Namespace: "ns-a", LabelSelector: "app=foo"},
+		TargetB: WorkloadTarget{Namespace: "ns-b", LabelSelector: "app=bar"},
+	})
+	policyNameA := "block-" + id + "-a"
+	policyNameB := "block-" + id + "-b"
 
 	// Setup with existing policies having correct names
 	clientset := fake.NewSimpleClientset(
@@ -269,34 +333,178 @@ func TestParseLabelSelector(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
-		expected map[string]string
+		expected *metav1.LabelSelector
+		err      bool
 	}{
 		{
 			name:     "Single label",
 			input:    "app=foo",
-			expected: map[string]string{"app": "foo"},
+			expected: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}},
 		},
 		{
 			name:     "Multiple labels",
 			input:    "app=foo,env=prod",
-			expected: map[string]string{"app": "foo", "env": "prod"},
+			expected: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo", "env": "prod"}},
 		},
 		{
 			name:     "Multiple labels with spaces",
 			input:    " app = foo , env = prod ",
-			expected: map[string]string{"app": "foo", "env": "prod"},
+			expected: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo", "env": "prod"}},
+		},
+		{
+			name:  "Set based terms",
+			input: "app=foo,env!=dev,tier in (web,api),track notin (canary),owner,!legacy",
+			expected: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "foo"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "env", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"dev"}},
+					{Key: "legacy", Operator: metav1.LabelSelectorOpDoesNotExist},
+					{Key: "owner", Operator: metav1.LabelSelectorOpExists},
+					{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"api", "web"}},
+					{Key: "track", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"canary"}},
+				},
+			},
 		},
 		{
-			name:     "Empty input",
-			input:    "",
-			expected: map[string]string{},
+			name:  "Repeated key keeps both terms",
+			input: "app=foo,app=bar",
+			expected: &metav1.LabelSelector{
+				MatchLabels:      map[string]string{"app": "foo"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"bar"}}},
+			},
+		},
+		{
+			name:  "Empty input",
+			input: "",
+			err:   true,
+		},
+		{
+			name:  "Numeric comparison",
+			input: "replicas>1",
+			err:   true,
+		},
+		{
+			name:  "Malformed",
+			input: "app in (foo",
+			err:   true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := parseLabelSelector(tt.input)
+			got, err := parseLabelSelector(tt.input)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
 			assert.Equal(t, tt.expected, got)
 		})
 	}
 }
+
+func TestGenerateBlockPolicy(t *testing.T) {
+	h := &Handler{}
+	policy, err := h.generateBlockPolicy("block-1-a",
+		WorkloadTarget{Namespace: "ns-a", LabelSelector: "app=foo"},
+		WorkloadTarget{Namespace: "ns-b", LabelSelector: "app=bar,tier in (web),!canary"},
+		DirectionIngress,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "foo"}, policy.Spec.PodSelector.MatchLabels)
+
+	// any namespace but ns-b, then one peer per complemented term of the blocked selector
+	peers := policy.Spec.Ingress[0].From
+	assert.Len(t, peers, 4)
+	var complements []metav1.LabelSelectorRequirement
+	for _, p := range peers[1:] {
+		assert.Equal(t, map[string]string{"kubernetes.io/metadata.name": "ns-b"}, p.NamespaceSelector.MatchLabels)
+		complements = append(complements, p.PodSelector.MatchExpressions...)
+	}
+	assert.Equal(t, []metav1.LabelSelectorRequirement{
+		{Key: "app", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"bar"}},
+		{Key: "canary", Operator: metav1.LabelSelectorOpExists},
+		{Key: "tier", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"web"}},
+	}, complements)
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}, policy.Spec.PolicyTypes)
+	assert.Empty(t, policy.Spec.Egress)
+}
+
+func TestGenerateBlockPolicy_Egress(t *testing.T) {
+	a := WorkloadTarget{Namespace: "ns-a", LabelSelector: "app=foo"}
+	b := WorkloadTarget{Namespace: "ns-b", LabelSelector: "app=bar"}
+
+	h := &Handler{PodCIDRs: []string{"10.244.0.0/16", "10.245.1.7/16", "fd00:10:244::/56"}}
+	policy, err := h.generateBlockPolicy("block-1-a", a, b, DirectionBoth)
+	assert.NoError(t, err)
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, policy.Spec.PolicyTypes)
+
+	// same pod peers as ingress, plus the addresses outside the pod CIDRs
+	to := policy.Spec.Egress[0].To
+	assert.Equal(t, policy.Spec.Ingress[0].From, to[:len(to)-2])
+	assert.Equal(t, []networkingv1.NetworkPolicyPeer{
+		{IPBlock: &networkingv1.IPBlock{CIDR: "0.0.0.0/0", Except: []string{"10.244.0.0/16", "10.245.0.0/16"}}},
+		{IPBlock: &networkingv1.IPBlock{CIDR: "::/0", Except: []string{"fd00:10:244::/56"}}},
+	}, to[len(to)-2:])
+
+	// without pod CIDRs egress would also drop every destination outside the pods
+	_, err = (&Handler{}).generateBlockPolicy("block-1-a", a, b, DirectionEgress)
+	assert.ErrorIs(t, err, errEgressWithoutPodCIDRs)
+}
+
+func TestBlockWorkloads_EgressWithoutPodCIDRs(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	h := &Handler{K8sClient: &k8s.Client{Clientset: clientset}}
+
+	for _, direction := range []string{DirectionEgress, DirectionBoth} {
+		body := `{"target_a": {"namespace": "ns-a", "label_selector": "app=foo"}, "target_b": {"namespace": "ns-b", "label_selector": "app=bar"}, "direction": "` + direction + `"}`
+		req, err := http.NewRequest("POST", "/api/v1/network/block", strings.NewReader(body))
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		h.BlockWorkloads(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, direction)
+		assert.Contains(t, rr.Body.String(), "NETWORK_POD_CIDRS")
+	}
+	assert.Empty(t, clientset.Actions())
+}
+
+func TestBlockWorkloads_InvalidSelector(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	h := &Handler{K8sClient: &k8s.Client{Clientset: clientset}}
+
+	for _, selector := range []string{"", "version>2", "app=foo,"} {
+		body := `{"target_a": {"namespace": "ns-a", "label_selector": "app=foo"}, "target_b": {"namespace": "ns-b", "label_selector": "` + selector + `"}}`
+		req, err := http.NewRequest("POST", "/api/v1/network/block", strings.NewReader(body))
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		h.BlockWorkloads(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, selector)
+	}
+	assert.Empty(t, clientset.Actions())
+}
+
+func TestListNetworkPolicies_Envelope(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy-b", Namespace: "ns-a"}},
+		&networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy-a", Namespace: "ns-b"}},
+	)
+	h := &Handler{K8sClient: &k8s.Client{Clientset: clientset}}
+
+	req, _ := http.NewRequest("GET", "/api/v1/network/policies?envelope=true&sort=name", nil)
+	rr := httptest.NewRecorder()
+	h.ListPolicies(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var env listing.Envelope[networkingv1.NetworkPolicy]
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &env))
+	assert.Equal(t, 2, env.Count)
+	assert.Equal(t, "policy-a", env.Items[0].Name)
+
+	// policies have no health
+	req, _ = http.NewRequest("GET", "/api/v1/network/policies?health=healthy", nil)
+	rr = httptest.NewRecorder()
+	h.ListPolicies(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,117 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/blocks"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EventReasonBlockExpired is the reason of the audit event recorded on each reaped policy.
+const EventReasonBlockExpired = "BlockExpired"
+
+// Reaper removes expired blocks of one cluster. The expiry is read back from the policy
+// annotations on every pass, so blocks created before a restart still expire.
+type Reaper struct {
+	Client   *k8s.Client
+	Cluster  string
+	Interval time.Duration
+}
+
+// Run reaps until ctx is done.
+func (rp *Reaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(rp.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := rp.Reap(ctx, time.Now()); err != nil {
+			fmt.Println("failed to reap expired blocks", rp.Cluster, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reap deletes the blocks expired at now and returns them. A block whose policies cannot all be
+// deleted is left for the next pass, its error joined to the returned one, and the other blocks
+// are still reaped.
+func (rp *Reaper) Reap(ctx context.Context, now time.Time) ([]blocks.Block, error) {
+	// a stale cache could report a block that is already gone
+	ctx = k8s.WithConsistentRead(ctx)
+	policies, err := rp.Client.ListNetworkPolicies(ctx, "", metav1.ListOptions{LabelSelector: blocks.LabelBlockID})
+	if err != nil {
+		return nil, err
+	}
+
+	var reaped []blocks.Block
+	var errs []error
+	for _, b := range blocks.FromPolicies(policies) {
+		if b.ExpiresAt == nil || now.Before(*b.ExpiresAt) {
+			continue
+		}
+
+		failed := false
+		for _, p := range b.Policies {
+			err := rp.Client.DeleteNetworkPolicy(ctx, p.Namespace, p.Name)
+			switch {
+			case apierrors.IsNotFound(err):
+				// removed by hand or by another replica, which audits its own delete
+			case err != nil:
+				errs = append(errs, fmt.Errorf("block %s: delete policy %s/%s: %w", b.ID, p.Namespace, p.Name, err))
+				failed = true
+			default:
+				rp.audit(ctx, b, p, now)
+			}
+		}
+		if failed {
+			continue
+		}
+		fmt.Printf("Block %s expired at %s, unblocked %s and %s\n", b.ID, b.ExpiresAt.Format(time.RFC3339), describeTarget(b.TargetA), describeTarget(b.TargetB))
+		reaped = append(reaped, b)
+	}
+	return reaped, errors.Join(errs...)
+}
+
+// audit records the removal as an event on the deleted policy, visible with kubectl get events.
+func (rp *Reaper) audit(ctx context.Context, b blocks.Block, p blocks.PolicyRef, now time.Time) {
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: p.Name + ".",
+			Namespace:    p.Namespace,
+			Labels:       map[string]string{blocks.LabelBlockID: b.ID, blocks.LabelManagedBy: blocks.ManagedBy},
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "networking.k8s.io/v1",
+			Kind:       "NetworkPolicy",
+			Namespace:  p.Namespace,
+			Name:       p.Name,
+			UID:        p.UID,
+		},
+		Type:   corev1.EventTypeNormal,
+		Reason: EventReasonBlockExpired,
+		Message: fmt.Sprintf("Block %s between %s and %s (created by %s) expired at %s, policy deleted",
+			b.ID, describeTarget(b.TargetA), describeTarget(b.TargetB), b.CreatedBy, b.ExpiresAt.Format(time.RFC3339)),
+		Source:              corev1.EventSource{Component: blocks.ManagedBy},
+		ReportingController: blocks.ManagedBy,
+		FirstTimestamp:      metav1.NewTime(now),
+		LastTimestamp:       metav1.NewTime(now),
+		Count:               1,
+	}
+	if _, err := rp.Client.CreateEvent(ctx, event); err != nil {
+		fmt.Println("failed to record block expiry event", b.ID, err)
+	}
+}
+
+func describeTarget(t WorkloadTarget) string {
+	return t.Namespace + "/" + t.LabelSelector
+}
@@ -0,0 +1,152 @@
+package network
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/blocks"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	testing2 "k8s.io/client-go/testing"
+)
+
+func TestBlockExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(2 * time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		req      BlockRequest
+		expected *time.Time
+		err      bool
+	}{
+		{"No expiry", BlockRequest{}, nil, false},
+		{"TTL", BlockRequest{TTL: "90m"}, ptr(now.Add(90 * time.Minute)), false},
+		{"Expires at", BlockRequest{ExpiresAt: &later}, &later, false},
+		{"Both", BlockRequest{TTL: "1h", ExpiresAt: &later}, nil, true},
+		{"Invalid TTL", BlockRequest{TTL: "an hour"}, nil, true},
+		{"Negative TTL", BlockRequest{TTL: "-1h"}, nil, true},
+		{"In the past", BlockRequest{ExpiresAt: &earlier}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := blockExpiry(tt.req, now)
+			assert.Equal(t, tt.err, err != nil)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestReaper(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	client := &k8s.Client{Clientset: clientset}
+	mux := blocksMux(&Handler{K8sClient: client})
+
+	rr := serve(mux, "POST", "/network/block",
+		`{"target_a": {"namespace": "ns-a", "label_selector": "app=foo"}, "target_b": {"namespace": "ns-b", "label_selector": "app=bar"}, "ttl": "1h"}`)
+	require.Equal(t, 200, rr.Code)
+	assert.Contains(t, rr.Body.String(), "expires_at")
+	rr = serve(mux, "POST", "/network/block",
+		`{"target_a": {"namespace": "ns-a", "label_selector": "app=foo"}, "target_b": {"namespace": "ns-c", "label_selector": "app=baz"}}`)
+	require.Equal(t, 200, rr.Code)
+
+	// a new reaper, as after a restart, reads the expiry back from the annotations
+	reaper := &Reaper{Client: client, Cluster: "prod"}
+	reaped, err := reaper.Reap(t.Context(), time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, reaped)
+
+	reaped, err = reaper.Reap(t.Context(), time.Now().Add(time.Hour+time.Minute))
+	require.NoError(t, err)
+	require.Len(t, reaped, 1)
+	assert.Equal(t, "ns-b", reaped[0].TargetB.Namespace)
+
+	policies, err := clientset.NetworkingV1().NetworkPolicies("").List(t.Context(), metav1.ListOptions{})
+	require.NoError(t, err)
+	// the block without expiry stays
+	assert.Len(t, policies.Items, 2)
+	for _, p := range policies.Items {
+		assert.NotContains(t, p.Annotations, blocks.AnnotationBlockExpiresAt)
+	}
+
+	events, err := clientset.CoreV1().Events("").List(t.Context(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, events.Items, 2)
+	for _, e := range events.Items {
+		assert.Equal(t, EventReasonBlockExpired, e.Reason)
+		assert.Equal(t, "NetworkPolicy", e.InvolvedObject.Kind)
+		assert.Equal(t, reaped[0].ID, e.Labels[blocks.LabelBlockID])
+	}
+}
+
+func TestReaper_FailedDelete(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	client := &k8s.Client{Clientset: clientset}
+	mux := blocksMux(&Handler{K8sClient: client})
+
+	for _, ns := range []string{"ns-b", "ns-c"} {
+		rr := serve(mux, "POST", "/network/block",
+			`{"target_a": {"namespace": "ns-a", "label_selector": "app=foo"}, "target_b": {"namespace": "`+ns+`", "label_selector": "app=bar"}, "ttl": "1h"}`)
+		require.Equal(t, 200, rr.Code)
+	}
+	clientset.PrependReactor("delete", "networkpolicies", func(action testing2.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() != "ns-b" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "networking.k8s.io", Resource: "networkpolicies"}, "", errors.New("rbac"))
+	})
+
+	reaper := &Reaper{Client: client, Cluster: "prod"}
+	reaped, err := reaper.Reap(t.Context(), time.Now().Add(2*time.Hour))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ns-b")
+	// the failing block does not hold back the other one
+	require.Len(t, reaped, 1)
+	assert.Equal(t, "ns-c", reaped[0].TargetB.Namespace)
+
+	policies, err := clientset.NetworkingV1().NetworkPolicies("").List(t.Context(), metav1.ListOptions{})
+	require.NoError(t, err)
+	// the ns-b side is retried on the next pass
+	require.Len(t, policies.Items, 1)
+	assert.Equal(t, "ns-b", policies.Items[0].Namespace)
+}
+
+func TestReaper_AlreadyDeleted(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	client := &k8s.Client{Clientset: clientset}
+	mux := blocksMux(&Handler{K8sClient: client})
+
+	rr := serve(mux, "POST", "/network/block",
+		`{"target_a": {"namespace": "ns-a", "label_selector": "app=foo"}, "target_b": {"namespace": "ns-b", "label_selector": "app=bar"}, "ttl": "1h"}`)
+	require.Equal(t, 200, rr.Code)
+	// the ns-b side goes away between the list and the delete, as when another replica reaps it
+	clientset.PrependReactor("delete", "networkpolicies", func(action testing2.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() != "ns-b" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "networking.k8s.io", Resource: "networkpolicies"}, "")
+	})
+
+	reaper := &Reaper{Client: client, Cluster: "prod"}
+	reaped, err := reaper.Reap(t.Context(), time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, reaped, 1)
+
+	// only the policy this reaper deleted is audited
+	events, err := clientset.CoreV1().Events("").List(t.Context(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, events.Items, 1)
+	assert.Equal(t, "ns-a", events.Items[0].InvolvedObject.Namespace)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	"github.com/moemoeq/tyk-sre-app/internal/rollout"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ReplicaSetSummary is a short view of a ReplicaSet owned by a Deployment.
+type ReplicaSetSummary struct {
+	Name          string `json:"name"`
+	Revision      string `json:"revision,omitempty"`
+	Replicas      int32  `json:"replicas"`
+	ReadyReplicas int32  `json:"ready_replicas"`
+}
+
+// DeploymentPods is the pod drill-down of a single Deployment.
+type DeploymentPods struct {
+	Namespace     string              `json:"namespace"`
+	Name          string              `json:"name"`
+	Health        bool                `json:"health"`
+	HealthReasons []health.Reason     `json:"health_reasons"`
+	ReplicaSets   []ReplicaSetSummary `json:"replica_sets"`
+	Pods          []health.PodReport  `json:"pods"`
+}
+
+// Resolves the deployment selector to its ReplicaSets and pods.
+func (api *API) getDeploymentPods(w http.ResponseWriter, r *http.Request) {
+	namespace := r.PathValue("namespace")
+	name := r.PathValue("name")
+
+	d, err := api.client(r.Context()).GetDeployment(r.Context(), namespace, name)
+	if err != nil {
+		api.respondError(w, errorStatus(err), err.Error())
+		return
+	}
+
+	replicaSets, err := api.client(r.Context()).ListOwnedReplicaSets(r.Context(), d)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := health.EvaluateDeployment(d)
+	response := DeploymentPods{
+		Namespace:     d.Namespace,
+		Name:          d.Name,
+		Health:        result.Healthy,
+		HealthReasons: result.Reasons,
+		ReplicaSets:   make([]ReplicaSetSummary, 0, len(replicaSets)),
+		Pods:          []health.PodReport{},
+	}
+
+	owners := make([]types.UID, 0, len(replicaSets))
+	for _, rs := range replicaSets {
+		owners = append(owners, rs.UID)
+
+		summary := ReplicaSetSummary{
+			Name:          rs.Name,
+			Revision:      rs.Annotations[rollout.RevisionAnnotation],
+			ReadyReplicas: rs.Status.ReadyReplicas,
+		}
+		if rs.Spec.Replicas != nil {
+			summary.Replicas = *rs.Spec.Replicas
+		}
+		response.ReplicaSets = append(response.ReplicaSets, summary)
+	}
+
+	pods, err := api.client(r.Context()).ListOwnedPods(r.Context(), d.Namespace, d.Spec.Selector, owners)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, p := range pods {
+		response.Pods = append(response.Pods, health.InspectPod(&p))
+	}
+
+	api.respondJSON(w, http.StatusOK, response)
+}
+
+// errorStatus maps Kubernetes API errors to HTTP status codes.
+func errorStatus(err error) int {
+	switch {
+	case apierrors.IsNotFound(err):
+		return http.StatusNotFound
+	case apierrors.IsBadRequest(err), apierrors.IsInvalid(err):
+		return http.StatusBadRequest
+	case apierrors.IsConflict(err), apierrors.IsAlreadyExists(err):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moemoeq/tyk-sre-app/internal/config"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/moemoeq/tyk-sre-app/internal/rollout"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetDeploymentPods(t *testing.T) {
+	labels := map[string]string{"app": "web"}
+	fakeClientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr(int32(1)),
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+			},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "web-5d9c",
+				Namespace:   "default",
+				UID:         "rs-uid",
+				Labels:      labels,
+				Annotations: map[string]string{rollout.RevisionAnnotation: "2"},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "Deployment", Name: "web", UID: "web-uid", Controller: ptr(true)},
+				},
+			},
+			Spec: appsv1.ReplicaSetSpec{Replicas: ptr(int32(1))},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-5d9c-abcde",
+				Namespace: "default",
+				Labels:    labels,
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "ReplicaSet", Name: "web-5d9c", UID: "rs-uid", Controller: ptr(true)},
+				},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "app", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+				},
+			},
+		},
+		// Same labels but not owned by the deployment
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "stray", Namespace: "default", Labels: labels},
+		},
+	)
+	api := &API{Config: &config.Config{}, K8sClient: &k8s.Client{Clientset: fakeClientset}}
+	mux := http.NewServeMux()
+	api.Register(mux)
+
+	req, _ := http.NewRequest("GET", "/deployments/default/web/pods", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp DeploymentPods
+	err := json.Unmarshal(rr.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.False(t, resp.Health)
+	assert.Equal(t, []ReplicaSetSummary{{Name: "web-5d9c", Revision: "2", Replicas: 1}}, resp.ReplicaSets)
+	assert.Len(t, resp.Pods, 1)
+	assert.Equal(t, "web-5d9c-abcde", resp.Pods[0].Name)
+	assert.Equal(t, "ImagePullBackOff", resp.Pods[0].Containers[0].Reason)
+
+	// Not found
+	req, _ = http.NewRequest("GET", "/deployments/default/missing/pods", nil)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
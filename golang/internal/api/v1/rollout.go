@@ -0,0 +1,121 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/moemoeq/tyk-sre-app/internal/rollout"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// rolloutPollInterval is how often ?wait=true re-reads the deployment.
+var rolloutPollInterval = 2 * time.Second
+
+func (api *API) restartDeployment(w http.ResponseWriter, r *http.Request) {
+	api.patchRollout(w, r, rollout.ActionRestart, types.StrategicMergePatchType, rollout.RestartPatch(time.Now()))
+}
+
+func (api *API) pauseDeployment(w http.ResponseWriter, r *http.Request) {
+	api.patchRollout(w, r, rollout.ActionPause, types.StrategicMergePatchType, rollout.PausePatch(true))
+}
+
+func (api *API) resumeDeployment(w http.ResponseWriter, r *http.Request) {
+	api.patchRollout(w, r, rollout.ActionResume, types.StrategicMergePatchType, rollout.PausePatch(false))
+}
+
+// Restores the pod template of ?revision=N, or of the previous revision if omitted.
+func (api *API) rollbackDeployment(w http.ResponseWriter, r *http.Request) {
+	d, err := api.client(r.Context()).GetDeployment(r.Context(), r.PathValue("namespace"), r.PathValue("name"))
+	if err != nil {
+		api.respondError(w, errorStatus(err), err.Error())
+		return
+	}
+
+	replicaSets, err := api.client(r.Context()).ListOwnedReplicaSets(r.Context(), d)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var target *appsv1.ReplicaSet
+	if rev := r.URL.Query().Get("revision"); rev != "" {
+		revision, err := strconv.ParseInt(rev, 10, 64)
+		if err != nil {
+			api.respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid revision %q", rev))
+			return
+		}
+		if target = rollout.FindRevision(replicaSets, revision); target == nil {
+			api.respondError(w, http.StatusNotFound, fmt.Sprintf("revision %d not found", revision))
+			return
+		}
+	} else {
+		current, _ := strconv.ParseInt(d.Annotations[rollout.RevisionAnnotation], 10, 64)
+		target = rollout.PreviousRevision(replicaSets, current)
+	}
+
+	if err := rollout.ValidateRollback(d, target); err != nil {
+		api.respondError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	patch, err := rollout.RollbackPatch(target)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.patchRollout(w, r, rollout.ActionRollback, types.JSONPatchType, patch)
+}
+
+// patchRollout applies the patch and responds with the rollout status,
+// with ?wait=true it blocks until the rollout is healthy or ?timeout elapses.
+func (api *API) patchRollout(w http.ResponseWriter, r *http.Request, action string, pt types.PatchType, patch []byte) {
+	namespace := r.PathValue("namespace")
+	name := r.PathValue("name")
+
+	wait := r.URL.Query().Get("wait") == "true"
+	timeout := time.Duration(api.Config.RolloutWaitTimeout) * time.Second
+	if t := r.URL.Query().Get("timeout"); t != "" {
+		parsed, err := time.ParseDuration(t)
+		if err != nil || parsed <= 0 {
+			api.respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid timeout %q", t))
+			return
+		}
+		timeout = min(parsed, timeout)
+	}
+
+	d, err := api.client(r.Context()).PatchDeployment(r.Context(), namespace, name, pt, patch)
+	if err != nil {
+		api.respondError(w, errorStatus(err), err.Error())
+		return
+	}
+
+	status := rollout.NewStatus(action, d)
+	if wait && action != rollout.ActionPause {
+		// outlive the server WriteTimeout while waiting
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout + 5*time.Second))
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		status, err = rollout.Wait(ctx, action, rolloutPollInterval, func(ctx context.Context) (*appsv1.Deployment, error) {
+			// the cache may still hold the pre-patch generation
+			return api.client(ctx).GetDeployment(k8s.WithConsistentRead(ctx), namespace, name)
+		})
+		if err != nil {
+			api.respondError(w, errorStatus(err), err.Error())
+			return
+		}
+	}
+
+	httpStatus := http.StatusOK
+	if status.TimedOut {
+		httpStatus = http.StatusGatewayTimeout
+	}
+	api.respondJSON(w, httpStatus, status)
+}
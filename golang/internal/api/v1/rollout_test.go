@@ -0,0 +1,128 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moemoeq/tyk-sre-app/internal/config"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/moemoeq/tyk-sre-app/internal/rollout"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func rolloutAPI() (*API, *fake.Clientset, *http.ServeMux) {
+	fakeClientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "web",
+				Namespace:   "default",
+				UID:         "web-uid",
+				Annotations: map[string]string{rollout.RevisionAnnotation: "2"},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr(int32(1)),
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "web:1.1"}}},
+				},
+			},
+		},
+		ownedReplicaSet("web-a", "1", "web:1.0"),
+		ownedReplicaSet("web-b", "2", "web:1.1"),
+	)
+	api := &API{
+		Config:    &config.Config{RolloutWaitTimeout: 1},
+		K8sClient: &k8s.Client{Clientset: fakeClientset},
+	}
+	mux := http.NewServeMux()
+	api.Register(mux)
+	return api, fakeClientset, mux
+}
+
+func TestRestartDeployment(t *testing.T) {
+	_, fakeClientset, mux := rolloutAPI()
+
+	req, _ := http.NewRequest("POST", "/deployments/default/web/restart", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var status rollout.Status
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &status))
+	assert.Equal(t, rollout.ActionRestart, status.Action)
+
+	d, _ := fakeClientset.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+	assert.NotEmpty(t, d.Spec.Template.Annotations[rollout.RestartedAtAnnotation])
+
+	// GET is not routed
+	req, _ = http.NewRequest("GET", "/deployments/default/web/restart", nil)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestPauseResumeDeployment(t *testing.T) {
+	_, fakeClientset, mux := rolloutAPI()
+
+	req, _ := http.NewRequest("POST", "/deployments/default/web/pause", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	d, _ := fakeClientset.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+	assert.True(t, d.Spec.Paused)
+
+	req, _ = http.NewRequest("POST", "/deployments/default/web/resume", nil)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	d, _ = fakeClientset.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+	assert.False(t, d.Spec.Paused)
+}
+
+func TestRollbackDeployment(t *testing.T) {
+	_, fakeClientset, mux := rolloutAPI()
+
+	// previous revision by default
+	req, _ := http.NewRequest("POST", "/deployments/default/web/rollback", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	d, _ := fakeClientset.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+	assert.Equal(t, "web:1.0", d.Spec.Template.Spec.Containers[0].Image)
+
+	// already running revision 1
+	req, _ = http.NewRequest("POST", "/deployments/default/web/rollback?revision=1", nil)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	req, _ = http.NewRequest("POST", "/deployments/default/web/rollback?revision=9", nil)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestRestartDeployment_WaitTimeout(t *testing.T) {
+	_, _, mux := rolloutAPI()
+
+	// nothing reconciles the fake deployment, so it never becomes healthy
+	req, _ := http.NewRequest("POST", "/deployments/default/web/restart?wait=true&timeout=50ms", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, rr.Code)
+	var status rollout.Status
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &status))
+	assert.True(t, status.TimedOut)
+	assert.False(t, status.Complete)
+}
@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	"github.com/moemoeq/tyk-sre-app/internal/rollout"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ScaleRequest struct {
+	Replicas *int32 `json:"replicas"`
+}
+
+// ScaleResult carries the previous replica count so the change can be undone.
+type ScaleResult struct {
+	Namespace        string `json:"namespace"`
+	Name             string `json:"name"`
+	PreviousReplicas int32  `json:"previous_replicas"`
+	Replicas         int32  `json:"replicas"`
+}
+
+// Scales a deployment through the scale subresource, within the configured guardrails.
+func (api *API) scaleDeployment(w http.ResponseWriter, r *http.Request) {
+	namespace := r.PathValue("namespace")
+	name := r.PathValue("name")
+
+	var req ScaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Replicas == nil {
+		api.respondError(w, http.StatusBadRequest, "invalid request body, expected {\"replicas\": N}")
+		return
+	}
+
+	guard := rollout.ScaleGuard{
+		MinReplicas: api.Config.ScaleMinReplicas,
+		MaxReplicas: api.Config.ScaleMaxReplicas,
+		Protected:   api.Config.ScaleProtectedNamespaces,
+	}
+	if err := guard.Check(namespace, *req.Replicas); err != nil {
+		api.respondError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	// An HPA would override the change on its next sync
+	hpas, err := api.client(r.Context()).ListHorizontalPodAutoscalers(r.Context(), namespace, metav1.ListOptions{})
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if hpa := health.FindHPA(hpas, namespace, health.KindDeployment, name); hpa != nil {
+		api.respondError(w, http.StatusConflict, fmt.Sprintf("deployment is managed by HorizontalPodAutoscaler %s, scale it instead", hpa.Name))
+		return
+	}
+
+	scale, err := api.client(r.Context()).GetDeploymentScale(r.Context(), namespace, name)
+	if err != nil {
+		api.respondError(w, errorStatus(err), err.Error())
+		return
+	}
+
+	result := ScaleResult{
+		Namespace:        namespace,
+		Name:             name,
+		PreviousReplicas: scale.Spec.Replicas,
+	}
+
+	// resourceVersion from the read guards against concurrent changes
+	scale.Spec.Replicas = *req.Replicas
+	updated, err := api.client(r.Context()).UpdateDeploymentScale(r.Context(), namespace, name, scale)
+	if err != nil {
+		api.respondError(w, errorStatus(err), err.Error())
+		return
+	}
+	result.Replicas = updated.Spec.Replicas
+
+	api.respondJSON(w, http.StatusOK, result)
+}
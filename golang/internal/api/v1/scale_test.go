@@ -0,0 +1,101 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/moemoeq/tyk-sre-app/internal/config"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/stretchr/testify/assert"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	testing2 "k8s.io/client-go/testing"
+)
+
+// fakeScale serves the deployments scale subresource, which the fake tracker does not support.
+func fakeScale(clientset *fake.Clientset, replicas int32) {
+	clientset.PrependReactor("get", "deployments", func(action testing2.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		get := action.(testing2.GetAction)
+		return true, &autoscalingv1.Scale{
+			ObjectMeta: metav1.ObjectMeta{Name: get.GetName(), Namespace: get.GetNamespace()},
+			Spec:       autoscalingv1.ScaleSpec{Replicas: replicas},
+		}, nil
+	})
+	clientset.PrependReactor("update", "deployments", func(action testing2.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		return true, action.(testing2.UpdateAction).GetObject(), nil
+	})
+}
+
+func TestScaleDeployment(t *testing.T) {
+	fakeClientset := fake.NewSimpleClientset()
+	fakeScale(fakeClientset, 3)
+	api := &API{
+		Config: &config.Config{
+			ScaleMaxReplicas:         map[string]int{"*": 10},
+			ScaleProtectedNamespaces: []string{"prod"},
+		},
+		K8sClient: &k8s.Client{Clientset: fakeClientset},
+	}
+	mux := http.NewServeMux()
+	api.Register(mux)
+
+	req, _ := http.NewRequest("PUT", "/deployments/default/web/scale", strings.NewReader(`{"replicas": 5}`))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var result ScaleResult
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+	assert.Equal(t, ScaleResult{Namespace: "default", Name: "web", PreviousReplicas: 3, Replicas: 5}, result)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"Missing replicas", "/deployments/default/web/scale", `{}`, http.StatusBadRequest},
+		{"Above maximum", "/deployments/default/web/scale", `{"replicas": 11}`, http.StatusUnprocessableEntity},
+		{"Zero in protected namespace", "/deployments/prod/web/scale", `{"replicas": 0}`, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("PUT", tt.path, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			assert.Equal(t, tt.status, rr.Code)
+		})
+	}
+}
+
+func TestScaleDeployment_HPA(t *testing.T) {
+	fakeClientset := fake.NewSimpleClientset(&autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-hpa", Namespace: "default"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+		},
+	})
+	fakeScale(fakeClientset, 3)
+	api := &API{Config: &config.Config{}, K8sClient: &k8s.Client{Clientset: fakeClientset}}
+	mux := http.NewServeMux()
+	api.Register(mux)
+
+	req, _ := http.NewRequest("PUT", "/deployments/default/web/scale", strings.NewReader(`{"replicas": 5}`))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "web-hpa")
+}
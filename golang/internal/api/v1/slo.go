@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/slo"
+)
+
+// Availability of each deployment over 7 and 30 days from the recorded health history,
+// with error budget and burn rate against its sre.tyk.io/slo-target annotation.
+func (api *API) getSLO(w http.ResponseWriter, r *http.Request) {
+	if api.History == nil {
+		api.respondError(w, http.StatusServiceUnavailable, "health history is disabled")
+		return
+	}
+
+	namespace, listOptions := listQuery(r)
+	deployments, err := api.client(r.Context()).ListDeployments(r.Context(), namespace, listOptions)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	reports, err := slo.Compute(api.History, api.client(r.Context()).Name(), deployments, time.Now())
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.respondJSON(w, http.StatusOK, reports)
+}
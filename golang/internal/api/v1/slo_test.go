@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/config"
+	"github.com/moemoeq/tyk-sre-app/internal/history"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/moemoeq/tyk-sre-app/internal/slo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetSLO(t *testing.T) {
+	store, err := history.Open(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	clusters := k8s.NewRegistry("prod")
+	clusters.Add("prod", &k8s.Client{Clientset: fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name: "web", Namespace: "default", Annotations: map[string]string{slo.AnnotationTarget: "99.9"},
+		}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "other"}},
+	)})
+	api := New(&config.Config{}, clusters)
+	mux := http.NewServeMux()
+	api.Register(mux)
+
+	req, _ := http.NewRequest("GET", "/slo", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	api.History = store
+	key := history.Key{Cluster: "prod", Namespace: "default", Name: "web"}
+	require.NoError(t, store.Record(key, history.Transition{Time: time.Now().Add(-40 * 24 * time.Hour), Healthy: true}))
+
+	req, _ = http.NewRequest("GET", "/slo?namespace=default", nil)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var reports []slo.DeploymentSLO
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &reports))
+	require.Len(t, reports, 1)
+	assert.Equal(t, "web", reports[0].Name)
+	assert.Equal(t, 99.9, *reports[0].Target)
+	require.Len(t, reports[0].Windows, 2)
+	assert.Equal(t, "7d", reports[0].Windows[0].Window)
+	assert.Equal(t, 100.0, *reports[0].Windows[0].Availability)
+	assert.Equal(t, 100.0, *reports[0].Windows[1].ErrorBudgetRemaining)
+}
@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/history"
+)
+
+// defaultTimelineWindow is the timeline range when ?start= is not set.
+const defaultTimelineWindow = 24 * time.Hour
+
+// DeploymentTimeline is the recorded health of a deployment over a time range.
+type DeploymentTimeline struct {
+	Cluster   string    `json:"cluster"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	// The first transition may predate Start, it holds the state at Start
+	Transitions []history.Transition `json:"transitions"`
+}
+
+// Health transitions recorded by the sampler between ?start= and ?end=, the last 24h by default.
+// The deployment does not need to exist anymore.
+func (api *API) getDeploymentTimeline(w http.ResponseWriter, r *http.Request) {
+	if api.History == nil {
+		api.respondError(w, http.StatusServiceUnavailable, "health history is disabled")
+		return
+	}
+
+	now := time.Now()
+	end, err := parseTimeQuery(r, "end", now, now)
+	if err != nil {
+		api.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	start, err := parseTimeQuery(r, "start", now, end.Add(-defaultTimelineWindow))
+	if err != nil {
+		api.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !start.Before(end) {
+		api.respondError(w, http.StatusBadRequest, "start must be before end")
+		return
+	}
+
+	key := history.Key{
+		Cluster:   api.client(r.Context()).Name(),
+		Namespace: r.PathValue("namespace"),
+		Name:      r.PathValue("name"),
+	}
+	transitions, err := api.History.Range(key, start, end)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.respondJSON(w, http.StatusOK, DeploymentTimeline{
+		Cluster:     key.Cluster,
+		Namespace:   key.Namespace,
+		Name:        key.Name,
+		Start:       start,
+		End:         end,
+		Transitions: transitions,
+	})
+}
+
+// parseTimeQuery reads an RFC3339 time or a duration before now ("6h") from the query.
+func parseTimeQuery(r *http.Request, name string, now, fallback time.Time) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid %s %q, expected RFC3339 time or duration", name, value)
+}
@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/config"
+	"github.com/moemoeq/tyk-sre-app/internal/history"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetDeploymentTimeline(t *testing.T) {
+	store, err := history.Open(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	clusters := k8s.NewRegistry("prod")
+	clusters.Add("prod", &k8s.Client{Clientset: fake.NewSimpleClientset()})
+	api := New(&config.Config{}, clusters)
+	api.History = store
+	mux := http.NewServeMux()
+	api.Register(mux)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	key := history.Key{Cluster: "prod", Namespace: "default", Name: "web"}
+	require.NoError(t, store.Record(key, history.Transition{Time: now.Add(-48 * time.Hour), Healthy: true}))
+	require.NoError(t, store.Record(key, history.Transition{Time: now.Add(-time.Hour), Healthy: false}))
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		count  int
+	}{
+		{"Last day by default", "", http.StatusOK, 2},
+		{"Duration start", "?start=30m", http.StatusOK, 1},
+		{"RFC3339 range", "?start=" + now.Add(-72*time.Hour).Format(time.RFC3339) + "&end=" + now.Add(-24*time.Hour).Format(time.RFC3339), http.StatusOK, 1},
+		{"Invalid start", "?start=yesterday", http.StatusBadRequest, 0},
+		{"Start after end", "?start=1h&end=2h", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/deployments/default/web/timeline"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			if tt.status == http.StatusOK {
+				var resp DeploymentTimeline
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				assert.Equal(t, "prod", resp.Cluster)
+				assert.Len(t, resp.Transitions, tt.count)
+			}
+		})
+	}
+
+	api.History = nil
+	req, _ := http.NewRequest("GET", "/deployments/default/web/timeline", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
@@ -0,0 +1,249 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// SSE event names on /deployments/watch.
+const (
+	EventTransition = "transition"
+	EventDeleted    = "deleted"
+	EventResync     = "resync"
+	EventError      = "error"
+)
+
+// heartbeatInterval keeps idle SSE connections open through proxies.
+var heartbeatInterval = 15 * time.Second
+
+// DeploymentHealthEvent is the data of an SSE event on /deployments/watch.
+// PreviousHealth is null when the previous state is unknown (new deployment or resumed stream).
+type DeploymentHealthEvent struct {
+	Deployment     EnrichedDeployment `json:"deployment"`
+	PreviousHealth *bool              `json:"previous_health"`
+	Health         bool               `json:"health"`
+	Reasons        []health.Reason    `json:"reasons"`
+}
+
+// Streams deployment health transitions as Server-Sent Events.
+// Event IDs are resourceVersions, so a reconnecting client resumes via Last-Event-ID.
+func (api *API) watchDeployments(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		api.respondError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	detailed := r.URL.Query().Get("detailed") == "true"
+	namespace, listOptions := listQuery(r)
+
+	// health by namespace/name, seeded from a list at the client's last event when resuming,
+	// so that only real flips after it are sent as transitions
+	resumeFrom := r.Header.Get("Last-Event-ID")
+	list, err := api.listForWatch(r.Context(), namespace, listOptions, resumeFrom)
+	resynced := false
+	if resumeFrom != "" && (apierrors.IsResourceExpired(err) || apierrors.IsGone(err)) {
+		// too old to resume, the client is told to refetch
+		list, err = api.listForWatch(r.Context(), namespace, listOptions, "")
+		resynced = true
+	}
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	previous := map[string]bool{}
+	for _, d := range list.Items {
+		previous[d.Namespace+"/"+d.Name] = health.EvaluateDeployment(&d).Healthy
+	}
+	resourceVersion := list.ResourceVersion
+	if resumeFrom != "" && !resynced {
+		resourceVersion = resumeFrom
+	}
+
+	// the server WriteTimeout would otherwise cut the stream
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if resynced {
+		writeSSE(w, EventResync, resourceVersion, map[string]string{"resource_version": resourceVersion})
+		flusher.Flush()
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		opts := listOptions
+		opts.ResourceVersion = resourceVersion
+		opts.AllowWatchBookmarks = true
+
+		watcher, err := api.client(r.Context()).WatchDeployments(r.Context(), namespace, opts)
+		if err != nil {
+			writeSSE(w, EventError, "", map[string]string{"error": err.Error()})
+			flusher.Flush()
+			return
+		}
+		fmt.Fprint(w, ": watching\n\n")
+		flusher.Flush()
+
+		resourceVersion, err = api.streamWatch(r.Context(), w, flusher, heartbeat, watcher, detailed, previous, resourceVersion)
+		watcher.Stop()
+		if r.Context().Err() != nil {
+			return
+		}
+
+		switch {
+		case err == nil:
+			// watch closed by the server, resume
+		case apierrors.IsResourceExpired(err) || apierrors.IsGone(err):
+			// too old to resume, start over from a fresh list and send what changed in the gap
+			list, err := api.listForWatch(r.Context(), namespace, listOptions, "")
+			if err != nil {
+				writeSSE(w, EventError, "", map[string]string{"error": err.Error()})
+				flusher.Flush()
+				return
+			}
+			resourceVersion = list.ResourceVersion
+			resyncHealth(w, list.Items, previous, detailed)
+			writeSSE(w, EventResync, resourceVersion, map[string]string{"resource_version": resourceVersion})
+			flusher.Flush()
+		default:
+			// client reconnects with Last-Event-ID
+			writeSSE(w, EventError, "", map[string]string{"error": err.Error()})
+			flusher.Flush()
+			return
+		}
+	}
+}
+
+// listForWatch lists the deployments as of resourceVersion, or the latest ones when empty.
+func (api *API) listForWatch(ctx context.Context, namespace string, opts metav1.ListOptions, resourceVersion string) (*appsv1.DeploymentList, error) {
+	if resourceVersion != "" {
+		opts.ResourceVersion = resourceVersion
+		opts.ResourceVersionMatch = metav1.ResourceVersionMatchExact
+	}
+	return api.client(ctx).ListDeploymentPage(ctx, namespace, opts)
+}
+
+// resyncHealth sends a transition for every deployment whose health differs from previous,
+// new ones included, and a deleted event for those gone, then replaces previous with items.
+// The events carry no id, the resync event that follows sets it.
+func resyncHealth(w http.ResponseWriter, items []appsv1.Deployment, previous map[string]bool, detailed bool) {
+	seen := map[string]bool{}
+	for _, d := range items {
+		key := d.Namespace + "/" + d.Name
+		seen[key] = true
+
+		enrichment := enrichDeployment(&d, nil, detailed)
+		prev, known := previous[key]
+		previous[key] = enrichment.Health
+		if known && prev == enrichment.Health {
+			continue
+		}
+		payload := DeploymentHealthEvent{Deployment: enrichment, Health: enrichment.Health, Reasons: enrichment.HealthReasons}
+		if known {
+			payload.PreviousHealth = &prev
+		}
+		writeSSE(w, EventTransition, "", payload)
+	}
+
+	for key, prev := range previous {
+		if seen[key] {
+			continue
+		}
+		delete(previous, key)
+		namespace, name, _ := strings.Cut(key, "/")
+		writeSSE(w, EventDeleted, "", DeploymentHealthEvent{
+			Deployment:     EnrichedDeployment{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}},
+			PreviousHealth: &prev,
+			Health:         prev,
+		})
+	}
+}
+
+// streamWatch emits events until the watch ends and returns the last seen resourceVersion.
+func (api *API) streamWatch(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, heartbeat *time.Ticker, watcher watch.Interface, detailed bool, previous map[string]bool, resourceVersion string) (string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion, ctx.Err()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				// server closed the watch, resume from the last resourceVersion
+				return resourceVersion, nil
+			}
+
+			if event.Type == watch.Error {
+				return resourceVersion, apierrors.FromObject(event.Object)
+			}
+
+			d, ok := event.Object.(*appsv1.Deployment)
+			if !ok {
+				continue
+			}
+			resourceVersion = d.ResourceVersion
+
+			if event.Type == watch.Bookmark {
+				// id only, updates the client's Last-Event-ID without dispatching
+				fmt.Fprintf(w, "id: %s\n\n", resourceVersion)
+				flusher.Flush()
+				continue
+			}
+
+			key := d.Namespace + "/" + d.Name
+			// transitions follow Health, which the autoscaler view does not change
+			enrichment := enrichDeployment(d, nil, detailed)
+			payload := DeploymentHealthEvent{
+				Deployment: enrichment,
+				Health:     enrichment.Health,
+				Reasons:    enrichment.HealthReasons,
+			}
+			if prev, known := previous[key]; known {
+				payload.PreviousHealth = &prev
+			}
+
+			if event.Type == watch.Deleted {
+				delete(previous, key)
+				writeSSE(w, EventDeleted, resourceVersion, payload)
+				flusher.Flush()
+				continue
+			}
+
+			previous[key] = enrichment.Health
+			if payload.PreviousHealth != nil && *payload.PreviousHealth == enrichment.Health {
+				continue
+			}
+			writeSSE(w, EventTransition, resourceVersion, payload)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, event, id string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		fmt.Println("failed to encode event", err)
+		return
+	}
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
@@ -0,0 +1,205 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/moemoeq/tyk-sre-app/internal/config"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWatchDeployments(t *testing.T) {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr(int32(1))},
+	}
+	fakeClientset := fake.NewSimpleClientset(deploy)
+	api := &API{Config: &config.Config{}, K8sClient: &k8s.Client{Clientset: fakeClientset}}
+	mux := http.NewServeMux()
+	api.Register(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/deployments/watch", nil)
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(res.Body)
+	line, err := reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, ": watching\n", line)
+
+	// unhealthy -> healthy
+	healthy := deploy.DeepCopy()
+	healthy.ResourceVersion = "42"
+	healthy.Status = appsv1.DeploymentStatus{
+		ReadyReplicas:   1,
+		UpdatedReplicas: 1,
+		Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: "True"},
+			{Type: appsv1.DeploymentProgressing, Status: "True"},
+		},
+	}
+	_, err = fakeClientset.AppsV1().Deployments("default").Update(ctx, healthy, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+
+	var id, event, data string
+	for data == "" {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return
+		}
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimSpace(strings.TrimPrefix(line, "id: "))
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event: "))
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+
+	assert.Equal(t, "42", id)
+	assert.Equal(t, EventTransition, event)
+
+	var payload DeploymentHealthEvent
+	assert.NoError(t, json.Unmarshal([]byte(data), &payload))
+	assert.Equal(t, "web", payload.Deployment.Name)
+	assert.NotNil(t, payload.PreviousHealth)
+	assert.False(t, *payload.PreviousHealth)
+	assert.True(t, payload.Health)
+	assert.Empty(t, payload.Reasons)
+}
+
+// readSSE reads the next event with data, skipping comments.
+func readSSE(t *testing.T, reader *bufio.Reader) (id, event, data string) {
+	for data == "" {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return
+		}
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimSpace(strings.TrimPrefix(line, "id: "))
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event: "))
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	return id, event, data
+}
+
+func TestWatchDeployments_Resume(t *testing.T) {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr(int32(1))},
+	}
+	fakeClientset := fake.NewSimpleClientset(deploy)
+	api := &API{Config: &config.Config{}, K8sClient: &k8s.Client{Clientset: fakeClientset}}
+	mux := http.NewServeMux()
+	api.Register(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/deployments/watch", nil)
+	req.Header.Set("Last-Event-ID", "7")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	reader := bufio.NewReader(res.Body)
+	line, err := reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, ": watching\n", line)
+
+	// still unhealthy, not a transition
+	relabelled := deploy.DeepCopy()
+	relabelled.ResourceVersion = "8"
+	relabelled.Labels = map[string]string{"team": "shop"}
+	_, err = fakeClientset.AppsV1().Deployments("default").Update(ctx, relabelled, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+
+	healthy := relabelled.DeepCopy()
+	healthy.ResourceVersion = "9"
+	healthy.Status = appsv1.DeploymentStatus{
+		ReadyReplicas:   1,
+		UpdatedReplicas: 1,
+		Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: "True"},
+			{Type: appsv1.DeploymentProgressing, Status: "True"},
+		},
+	}
+	_, err = fakeClientset.AppsV1().Deployments("default").Update(ctx, healthy, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+
+	id, event, data := readSSE(t, reader)
+	assert.Equal(t, "9", id)
+	assert.Equal(t, EventTransition, event)
+	var payload DeploymentHealthEvent
+	assert.NoError(t, json.Unmarshal([]byte(data), &payload))
+	if assert.NotNil(t, payload.PreviousHealth, "seeded from a list on resume") {
+		assert.False(t, *payload.PreviousHealth)
+	}
+	assert.True(t, payload.Health)
+}
+
+func TestResyncHealth(t *testing.T) {
+	deployment := func(name string, ready int32) appsv1.Deployment {
+		return appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr(int32(1))},
+			Status: appsv1.DeploymentStatus{
+				ReadyReplicas:   ready,
+				UpdatedReplicas: 1,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: "True"},
+					{Type: appsv1.DeploymentProgressing, Status: "True"},
+				},
+			},
+		}
+	}
+	previous := map[string]bool{"default/flipped": true, "default/same": false, "default/gone": true}
+	items := []appsv1.Deployment{deployment("flipped", 0), deployment("same", 0), deployment("new", 1)}
+
+	rr := httptest.NewRecorder()
+	resyncHealth(rr, items, previous, false)
+
+	events := strings.Count(rr.Body.String(), "event: ")
+	reader := bufio.NewReader(rr.Body)
+	var got []string
+	for range events {
+		_, event, data := readSSE(t, reader)
+		var payload DeploymentHealthEvent
+		assert.NoError(t, json.Unmarshal([]byte(data), &payload))
+		prev := "null"
+		if payload.PreviousHealth != nil {
+			prev = strconv.FormatBool(*payload.PreviousHealth)
+		}
+		got = append(got, event+" "+payload.Deployment.Name+" "+prev+" -> "+strconv.FormatBool(payload.Health))
+	}
+	assert.Equal(t, []string{
+		"transition flipped true -> false",
+		"transition new null -> true",
+		"deleted gone true -> true",
+	}, got)
+	assert.Equal(t, map[string]bool{"default/flipped": false, "default/same": false, "default/new": true}, previous)
+}
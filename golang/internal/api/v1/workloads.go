@@ -0,0 +1,180 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (api *API) getStatefulSets(w http.ResponseWriter, r *http.Request) {
+	detailed := r.URL.Query().Get("detailed") == "true"
+	namespace, listOptions := listQuery(r)
+
+	sets, err := api.client(r.Context()).ListStatefulSets(r.Context(), namespace, listOptions)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]any, 0, len(sets))
+	for _, s := range sets {
+		result := health.EvaluateStatefulSet(&s)
+
+		enrichment := EnrichedStatefulSet{
+			TypeMeta:      s.TypeMeta,
+			ObjectMeta:    s.ObjectMeta,
+			Status:        s.Status,
+			Health:        result.Healthy,
+			HealthReasons: result.Reasons,
+		}
+
+		if detailed {
+			enrichment.Spec = &s.Spec
+		} else {
+			enrichment.ManagedFields = nil
+		}
+
+		response = append(response, enrichment)
+	}
+
+	api.respondJSON(w, http.StatusOK, response)
+}
+
+func (api *API) getDaemonSets(w http.ResponseWriter, r *http.Request) {
+	detailed := r.URL.Query().Get("detailed") == "true"
+	namespace, listOptions := listQuery(r)
+
+	sets, err := api.client(r.Context()).ListDaemonSets(r.Context(), namespace, listOptions)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]any, 0, len(sets))
+	for _, s := range sets {
+		result := health.EvaluateDaemonSet(&s)
+
+		enrichment := EnrichedDaemonSet{
+			TypeMeta:      s.TypeMeta,
+			ObjectMeta:    s.ObjectMeta,
+			Status:        s.Status,
+			Health:        result.Healthy,
+			HealthReasons: result.Reasons,
+		}
+
+		if detailed {
+			enrichment.Spec = &s.Spec
+		} else {
+			enrichment.ManagedFields = nil
+		}
+
+		response = append(response, enrichment)
+	}
+
+	api.respondJSON(w, http.StatusOK, response)
+}
+
+func (api *API) getReplicaSets(w http.ResponseWriter, r *http.Request) {
+	detailed := r.URL.Query().Get("detailed") == "true"
+	namespace, listOptions := listQuery(r)
+
+	sets, err := api.client(r.Context()).ListReplicaSets(r.Context(), namespace, listOptions)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]any, 0, len(sets))
+	for _, s := range sets {
+		result := health.EvaluateReplicaSet(&s)
+
+		enrichment := EnrichedReplicaSet{
+			TypeMeta:      s.TypeMeta,
+			ObjectMeta:    s.ObjectMeta,
+			Status:        s.Status,
+			Health:        result.Healthy,
+			HealthReasons: result.Reasons,
+		}
+
+		if detailed {
+			enrichment.Spec = &s.Spec
+		} else {
+			enrichment.ManagedFields = nil
+		}
+
+		response = append(response, enrichment)
+	}
+
+	api.respondJSON(w, http.StatusOK, response)
+}
+
+// Combined health view of Deployments, StatefulSets, DaemonSets
+// and ReplicaSets that are not managed by a Deployment.
+func (api *API) getWorkloads(w http.ResponseWriter, r *http.Request) {
+	namespace, listOptions := listQuery(r)
+
+	workloads, err := api.listWorkloads(r.Context(), namespace, listOptions)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.respondJSON(w, http.StatusOK, workloads)
+}
+
+// Compact health rollup per namespace and per kind.
+func (api *API) getHealthSummary(w http.ResponseWriter, r *http.Request) {
+	namespace, listOptions := listQuery(r)
+
+	workloads, err := api.listWorkloads(r.Context(), namespace, listOptions)
+	if err != nil {
+		api.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.respondJSON(w, http.StatusOK, health.Summarize(workloads))
+}
+
+func (api *API) listWorkloads(ctx context.Context, namespace string, opts metav1.ListOptions) ([]health.Workload, error) {
+	workloads := []health.Workload{}
+
+	deployments, err := api.client(ctx).ListDeployments(ctx, namespace, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deployments {
+		workloads = append(workloads, health.NewWorkload(health.KindDeployment, d.Namespace, d.Name, health.EvaluateDeployment(&d)))
+	}
+
+	statefulSets, err := api.client(ctx).ListStatefulSets(ctx, namespace, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range statefulSets {
+		workloads = append(workloads, health.NewWorkload(health.KindStatefulSet, s.Namespace, s.Name, health.EvaluateStatefulSet(&s)))
+	}
+
+	daemonSets, err := api.client(ctx).ListDaemonSets(ctx, namespace, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range daemonSets {
+		workloads = append(workloads, health.NewWorkload(health.KindDaemonSet, s.Namespace, s.Name, health.EvaluateDaemonSet(&s)))
+	}
+
+	replicaSets, err := api.client(ctx).ListReplicaSets(ctx, namespace, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range replicaSets {
+		// Deployment managed ReplicaSets are already covered by their Deployment
+		if owner := metav1.GetControllerOf(&s); owner != nil && owner.Kind == health.KindDeployment {
+			continue
+		}
+		workloads = append(workloads, health.NewWorkload(health.KindReplicaSet, s.Namespace, s.Name, health.EvaluateReplicaSet(&s)))
+	}
+
+	return workloads, nil
+}
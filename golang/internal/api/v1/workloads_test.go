@@ -0,0 +1,154 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetStatefulSets(t *testing.T) {
+	fakeClientset := fake.NewSimpleClientset(&appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "db",
+			Namespace: "default",
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: ptr(int32(3)),
+		},
+		Status: appsv1.StatefulSetStatus{
+			ReadyReplicas:   3,
+			UpdatedReplicas: 3,
+			CurrentRevision: "db-1",
+			UpdateRevision:  "db-2",
+		},
+	})
+	api := &API{K8sClient: &k8s.Client{Clientset: fakeClientset}}
+
+	req, _ := http.NewRequest("GET", "/statefulsets", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(api.getStatefulSets).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var sets []EnrichedStatefulSet
+	err := json.Unmarshal(rr.Body.Bytes(), &sets)
+	assert.NoError(t, err)
+	assert.Len(t, sets, 1)
+	assert.False(t, sets[0].Health)
+	assert.Equal(t, health.CheckCurrentRevision, sets[0].HealthReasons[0].Check)
+	assert.Nil(t, sets[0].Spec)
+}
+
+func TestGetDaemonSets(t *testing.T) {
+	fakeClientset := fake.NewSimpleClientset(&appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "node-agent",
+			Namespace: "kube-system",
+		},
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 2,
+			NumberReady:            2,
+			UpdatedNumberScheduled: 2,
+		},
+	})
+	api := &API{K8sClient: &k8s.Client{Clientset: fakeClientset}}
+
+	req, _ := http.NewRequest("GET", "/daemonsets?namespace=kube-system", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(api.getDaemonSets).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var sets []EnrichedDaemonSet
+	err := json.Unmarshal(rr.Body.Bytes(), &sets)
+	assert.NoError(t, err)
+	assert.Len(t, sets, 1)
+	assert.True(t, sets[0].Health)
+	assert.Empty(t, sets[0].HealthReasons)
+}
+
+func TestGetWorkloads(t *testing.T) {
+	fakeClientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr(int32(1))},
+		},
+		// Owned by the "web" Deployment, must not be listed separately
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-5d9c",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "Deployment", Name: "web", UID: "web-uid", Controller: ptr(true)},
+				},
+			},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "default"},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-agent", Namespace: "default"},
+		},
+	)
+	api := &API{K8sClient: &k8s.Client{Clientset: fakeClientset}}
+
+	req, _ := http.NewRequest("GET", "/workloads", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(api.getWorkloads).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var workloads []health.Workload
+	err := json.Unmarshal(rr.Body.Bytes(), &workloads)
+	assert.NoError(t, err)
+
+	var refs []health.Ref
+	for _, w := range workloads {
+		refs = append(refs, w.Ref)
+	}
+	assert.ElementsMatch(t, []health.Ref{
+		{Kind: health.KindDeployment, Namespace: "default", Name: "web"},
+		{Kind: health.KindStatefulSet, Namespace: "default", Name: "db"},
+		{Kind: health.KindDaemonSet, Namespace: "default", Name: "node-agent"},
+		{Kind: health.KindReplicaSet, Namespace: "default", Name: "legacy"},
+	}, refs)
+}
+
+func TestGetHealthSummary(t *testing.T) {
+	fakeClientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", Labels: map[string]string{"team": "shop"}},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr(int32(1))},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-agent", Namespace: "kube-system", Labels: map[string]string{"team": "infra"}},
+		},
+	)
+	api := &API{K8sClient: &k8s.Client{Clientset: fakeClientset}}
+
+	req, _ := http.NewRequest("GET", "/health/summary?labelSelector=team=shop", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(api.getHealthSummary).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var summary health.Summary
+	err := json.Unmarshal(rr.Body.Bytes(), &summary)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.Total)
+	assert.Equal(t, 1, summary.Unhealthy)
+	assert.Contains(t, summary.Namespaces, "shop")
+	assert.NotContains(t, summary.Namespaces, "kube-system")
+	assert.Equal(t, []health.Ref{{Kind: health.KindDeployment, Namespace: "shop", Name: "web"}}, summary.UnhealthyWorkloads)
+}
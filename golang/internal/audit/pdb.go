@@ -0,0 +1,145 @@
+// Package audit reports configuration risks across workloads.
+package audit
+
+import (
+	"sort"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// UncoveredWorkload is a workload no PodDisruptionBudget selects.
+type UncoveredWorkload struct {
+	health.Ref
+	Replicas int32 `json:"replicas"`
+}
+
+// PDBFinding describes a PodDisruptionBudget that blocks or misses drains.
+type PDBFinding struct {
+	Namespace          string       `json:"namespace"`
+	Name               string       `json:"name"`
+	Selector           string       `json:"selector"`
+	MatchedPods        int          `json:"matched_pods"`
+	CurrentHealthy     int32        `json:"current_healthy"`
+	DesiredHealthy     int32        `json:"desired_healthy"`
+	DisruptionsAllowed int32        `json:"disruptions_allowed"`
+	Workloads          []health.Ref `json:"workloads"`
+}
+
+// PDBReport is the PodDisruptionBudget coverage report.
+type PDBReport struct {
+	// Deployments and StatefulSets whose pod template no PDB selects
+	Uncovered []UncoveredWorkload `json:"uncovered"`
+	// PDBs that block any eviction right now, a node drain would hang
+	ZeroDisruptions []PDBFinding `json:"zero_disruptions"`
+	// PDBs whose selector matches no pod, most likely a stale or mistyped selector
+	NoPods []PDBFinding `json:"no_pods"`
+}
+
+// workload is the part of a Deployment or StatefulSet the audit needs.
+type workload struct {
+	ref      health.Ref
+	replicas int32
+	labels   labels.Set
+}
+
+// AuditPDBs matches PDB selectors against workload pod templates and running pods.
+func AuditPDBs(deployments []appsv1.Deployment, statefulSets []appsv1.StatefulSet, pdbs []policyv1.PodDisruptionBudget, pods []corev1.Pod) PDBReport {
+	report := PDBReport{
+		Uncovered:       []UncoveredWorkload{},
+		ZeroDisruptions: []PDBFinding{},
+		NoPods:          []PDBFinding{},
+	}
+
+	workloads := make([]workload, 0, len(deployments)+len(statefulSets))
+	for _, d := range deployments {
+		workloads = append(workloads, workload{
+			ref:      health.Ref{Kind: health.KindDeployment, Namespace: d.Namespace, Name: d.Name},
+			replicas: replicas(d.Spec.Replicas),
+			labels:   d.Spec.Template.Labels,
+		})
+	}
+	for _, s := range statefulSets {
+		workloads = append(workloads, workload{
+			ref:      health.Ref{Kind: health.KindStatefulSet, Namespace: s.Namespace, Name: s.Name},
+			replicas: replicas(s.Spec.Replicas),
+			labels:   s.Spec.Template.Labels,
+		})
+	}
+
+	covered := make([]bool, len(workloads))
+	for _, pdb := range pdbs {
+		// a nil selector selects nothing, an empty one the whole namespace
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			selector = labels.Nothing()
+		}
+
+		finding := PDBFinding{
+			Namespace:          pdb.Namespace,
+			Name:               pdb.Name,
+			Selector:           selector.String(),
+			CurrentHealthy:     pdb.Status.CurrentHealthy,
+			DesiredHealthy:     pdb.Status.DesiredHealthy,
+			DisruptionsAllowed: pdb.Status.DisruptionsAllowed,
+			Workloads:          []health.Ref{},
+		}
+
+		for i, w := range workloads {
+			if w.ref.Namespace == pdb.Namespace && selector.Matches(w.labels) {
+				covered[i] = true
+				finding.Workloads = append(finding.Workloads, w.ref)
+			}
+		}
+		for _, p := range pods {
+			if p.Namespace == pdb.Namespace && selector.Matches(labels.Set(p.Labels)) {
+				finding.MatchedPods++
+			}
+		}
+
+		switch {
+		case finding.MatchedPods == 0:
+			report.NoPods = append(report.NoPods, finding)
+		case pdb.Status.DisruptionsAllowed == 0:
+			report.ZeroDisruptions = append(report.ZeroDisruptions, finding)
+		}
+	}
+
+	for i, w := range workloads {
+		if !covered[i] {
+			report.Uncovered = append(report.Uncovered, UncoveredWorkload{Ref: w.ref, Replicas: w.replicas})
+		}
+	}
+
+	sortFindings(report.ZeroDisruptions)
+	sortFindings(report.NoPods)
+	sort.SliceStable(report.Uncovered, func(i, j int) bool {
+		a, b := report.Uncovered[i], report.Uncovered[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	return report
+}
+
+func sortFindings(findings []PDBFinding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Namespace != findings[j].Namespace {
+			return findings[i].Namespace < findings[j].Namespace
+		}
+		return findings[i].Name < findings[j].Name
+	})
+}
+
+func replicas(r *int32) int32 {
+	if r == nil {
+		return 0
+	}
+	return *r
+}
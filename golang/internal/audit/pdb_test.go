@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func deployment(namespace, name string, labels map[string]string) appsv1.Deployment {
+	replicas := int32(2)
+	return appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: labels}},
+		},
+	}
+}
+
+func pdb(namespace, name string, selector *metav1.LabelSelector, allowed int32) policyv1.PodDisruptionBudget {
+	return policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: selector},
+		Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed},
+	}
+}
+
+func pod(namespace, name string, labels map[string]string) corev1.Pod {
+	return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels}}
+}
+
+func TestAuditPDBs(t *testing.T) {
+	web := map[string]string{"app": "web"}
+	api := map[string]string{"app": "api"}
+	db := map[string]string{"app": "db"}
+
+	deployments := []appsv1.Deployment{
+		deployment("default", "web", web),
+		deployment("default", "api", api),
+		// same labels in another namespace, the PDB does not reach it
+		deployment("other", "web", web),
+	}
+	statefulSets := []appsv1.StatefulSet{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db"},
+		Spec:       appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: db}}},
+	}}
+	pdbs := []policyv1.PodDisruptionBudget{
+		pdb("default", "web", &metav1.LabelSelector{MatchLabels: web}, 1),
+		pdb("default", "db", &metav1.LabelSelector{MatchLabels: db}, 0),
+		pdb("default", "typo", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "wbe"}}, 0),
+		pdb("default", "nil-selector", nil, 0),
+	}
+	pods := []corev1.Pod{
+		pod("default", "web-1", web),
+		pod("default", "db-0", db),
+		pod("other", "web-1", web),
+	}
+
+	report := AuditPDBs(deployments, statefulSets, pdbs, pods)
+
+	assert.Equal(t, []UncoveredWorkload{
+		{Ref: health.Ref{Kind: health.KindDeployment, Namespace: "default", Name: "api"}, Replicas: 2},
+		{Ref: health.Ref{Kind: health.KindDeployment, Namespace: "other", Name: "web"}, Replicas: 2},
+	}, report.Uncovered)
+
+	if assert.Len(t, report.ZeroDisruptions, 1) {
+		assert.Equal(t, "db", report.ZeroDisruptions[0].Name)
+		assert.Equal(t, 1, report.ZeroDisruptions[0].MatchedPods)
+		assert.Equal(t, []health.Ref{{Kind: health.KindStatefulSet, Namespace: "default", Name: "db"}}, report.ZeroDisruptions[0].Workloads)
+	}
+
+	names := []string{}
+	for _, f := range report.NoPods {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"nil-selector", "typo"}, names)
+}
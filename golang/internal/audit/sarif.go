@@ -0,0 +1,112 @@
+package audit
+
+// SARIF 2.1.0, the subset code scanning dashboards read.
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "tyk-sre-app"
+)
+
+type SarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+type SarifRun struct {
+	Tool    SarifTool     `json:"tool"`
+	Results []SarifResult `json:"results"`
+}
+
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+type SarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []SarifRule `json:"rules"`
+}
+
+type SarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     SarifMessage       `json:"shortDescription"`
+	DefaultConfiguration SarifConfiguration `json:"defaultConfiguration"`
+}
+
+type SarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+type SarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             SarifMessage      `json:"message"`
+	Locations           []SarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type SarifLocation struct {
+	PhysicalLocation SarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []SarifLogicalLocation `json:"logicalLocations"`
+}
+
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+}
+
+type SarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type SarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// ToSarif converts workload findings. Workloads are not files, so the artifact URI
+// is the "<namespace>/<kind>/<name>" path of the live object.
+func ToSarif(findings []Finding) SarifLog {
+	rules := make([]SarifRule, 0, len(WorkloadRules))
+	index := map[string]int{}
+	for i, r := range WorkloadRules {
+		index[r.ID] = i
+		rules = append(rules, SarifRule{
+			ID:                   r.ID,
+			ShortDescription:     SarifMessage{Text: r.Description},
+			DefaultConfiguration: SarifConfiguration{Level: r.Severity},
+		})
+	}
+
+	results := make([]SarifResult, 0, len(findings))
+	for _, f := range findings {
+		object := f.Namespace + "/" + f.Kind + "/" + f.Name
+		results = append(results, SarifResult{
+			RuleID:    f.Rule,
+			RuleIndex: index[f.Rule],
+			Level:     f.Severity,
+			Message:   SarifMessage{Text: f.Message},
+			Locations: []SarifLocation{{
+				PhysicalLocation: SarifPhysicalLocation{ArtifactLocation: SarifArtifactLocation{URI: object}},
+				LogicalLocations: []SarifLogicalLocation{{FullyQualifiedName: object + "/" + f.Container, Kind: "resource"}},
+			}},
+			// stable across runs so dashboards track a finding instead of reopening it
+			PartialFingerprints: map[string]string{"workloadContainer/v1": f.Rule + ":" + object + "/" + f.Container},
+		})
+	}
+
+	return SarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []SarifRun{{
+			Tool:    SarifTool{Driver: SarifDriver{Name: toolName, Rules: rules}},
+			Results: results,
+		}},
+	}
+}
@@ -0,0 +1,245 @@
+package audit
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Pod Security Standards levels, least to most restrictive.
+// https://kubernetes.io/docs/concepts/security/pod-security-standards/
+const (
+	LevelPrivileged = "privileged"
+	LevelBaseline   = "baseline"
+	LevelRestricted = "restricted"
+	// not part of the standards, reported without lowering the level
+	LevelHardening = "hardening"
+)
+
+// Pod Security Admission namespace labels.
+const (
+	EnforceLabel        = "pod-security.kubernetes.io/enforce"
+	EnforceVersionLabel = "pod-security.kubernetes.io/enforce-version"
+	AuditLabel          = "pod-security.kubernetes.io/audit"
+	WarnLabel           = "pod-security.kubernetes.io/warn"
+)
+
+// Pod security check names.
+const (
+	CheckPrivileged               = "privileged"
+	CheckHostNamespaces           = "host_namespaces"
+	CheckHostPath                 = "host_path_volumes"
+	CheckHostPorts                = "host_ports"
+	CheckBaselineCapabilities     = "capabilities_baseline"
+	CheckSeccompUnconfined        = "seccomp_unconfined"
+	CheckVolumeTypes              = "volume_types"
+	CheckRunAsNonRoot             = "run_as_non_root"
+	CheckRunAsUser                = "run_as_user"
+	CheckAllowPrivilegeEscalation = "allow_privilege_escalation"
+	CheckDropCapabilities         = "capabilities_restricted"
+	CheckSeccompProfile           = "seccomp_profile"
+	CheckReadOnlyRootFilesystem   = "read_only_root_filesystem"
+)
+
+// Capabilities the baseline standard allows to add.
+var baselineCapabilities = []corev1.Capability{
+	"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD",
+	"NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT",
+}
+
+// Violation is a pod template field that breaks a standard.
+type Violation struct {
+	Level     string `json:"level"` // the standard violated
+	Check     string `json:"check"`
+	Container string `json:"container,omitempty"`
+	Message   string `json:"message"`
+}
+
+// PodSecurityResult is the Pod Security Standards evaluation of a workload.
+type PodSecurityResult struct {
+	health.Ref
+	// Most restrictive standard the pod template satisfies
+	Level      string      `json:"level"`
+	Violations []Violation `json:"violations"`
+}
+
+// NamespacePosture reports the Pod Security Admission labels of a namespace.
+type NamespacePosture struct {
+	Name           string `json:"name"`
+	Enforce        string `json:"enforce,omitempty"`
+	EnforceVersion string `json:"enforce_version,omitempty"`
+	Audit          string `json:"audit,omitempty"`
+	Warn           string `json:"warn,omitempty"`
+	// Enforce is baseline or restricted
+	Enforced bool `json:"enforced"`
+}
+
+// SecurityReport is the pod security posture of workloads and namespaces.
+type SecurityReport struct {
+	Workloads  []PodSecurityResult `json:"workloads"`
+	Namespaces []NamespacePosture  `json:"namespaces"`
+	Counts     map[string]int      `json:"counts"` // workloads by level
+}
+
+// EvaluatePodSecurity checks a pod template against the baseline and restricted standards.
+func EvaluatePodSecurity(ref health.Ref, spec *corev1.PodSpec) PodSecurityResult {
+	res := PodSecurityResult{Ref: ref, Violations: []Violation{}}
+	violate := func(level, check, container, format string, args ...any) {
+		res.Violations = append(res.Violations, Violation{Level: level, Check: check, Container: container, Message: fmt.Sprintf(format, args...)})
+	}
+
+	pod := spec.SecurityContext
+	if pod == nil {
+		pod = &corev1.PodSecurityContext{}
+	}
+
+	// baseline, pod level
+	if spec.HostNetwork || spec.HostPID || spec.HostIPC {
+		violate(LevelBaseline, CheckHostNamespaces, "", "hostNetwork=%t, hostPID=%t, hostIPC=%t", spec.HostNetwork, spec.HostPID, spec.HostIPC)
+	}
+	for _, v := range spec.Volumes {
+		switch {
+		case v.HostPath != nil:
+			violate(LevelBaseline, CheckHostPath, "", "volume %q mounts host path %s", v.Name, v.HostPath.Path)
+		case !restrictedVolume(v.VolumeSource):
+			violate(LevelRestricted, CheckVolumeTypes, "", "volume %q has a type the restricted standard does not allow", v.Name)
+		}
+	}
+	if isUnconfined(pod.SeccompProfile) {
+		violate(LevelBaseline, CheckSeccompUnconfined, "", "pod seccompProfile is Unconfined")
+	}
+	if pod.RunAsUser != nil && *pod.RunAsUser == 0 {
+		violate(LevelRestricted, CheckRunAsUser, "", "pod runAsUser is 0")
+	}
+
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		sc := c.SecurityContext
+		if sc == nil {
+			sc = &corev1.SecurityContext{}
+		}
+
+		// baseline
+		if sc.Privileged != nil && *sc.Privileged {
+			violate(LevelBaseline, CheckPrivileged, c.Name, "container is privileged")
+		}
+		for _, p := range c.Ports {
+			if p.HostPort != 0 {
+				violate(LevelBaseline, CheckHostPorts, c.Name, "container binds host port %d", p.HostPort)
+			}
+		}
+		if sc.Capabilities != nil {
+			for _, capability := range sc.Capabilities.Add {
+				if !slices.Contains(baselineCapabilities, capability) {
+					violate(LevelBaseline, CheckBaselineCapabilities, c.Name, "container adds capability %s", capability)
+				}
+			}
+		}
+		if isUnconfined(sc.SeccompProfile) {
+			violate(LevelBaseline, CheckSeccompUnconfined, c.Name, "container seccompProfile is Unconfined")
+		}
+
+		// restricted
+		if !boolOr(sc.RunAsNonRoot, pod.RunAsNonRoot) {
+			violate(LevelRestricted, CheckRunAsNonRoot, c.Name, "runAsNonRoot is not true")
+		}
+		if sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+			violate(LevelRestricted, CheckRunAsUser, c.Name, "container runAsUser is 0")
+		}
+		if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			violate(LevelRestricted, CheckAllowPrivilegeEscalation, c.Name, "allowPrivilegeEscalation is not false")
+		}
+		if sc.Capabilities == nil || !slices.Contains(sc.Capabilities.Drop, "ALL") {
+			violate(LevelRestricted, CheckDropCapabilities, c.Name, "capabilities do not drop ALL")
+		}
+		if sc.Capabilities != nil {
+			for _, capability := range sc.Capabilities.Add {
+				if capability != "NET_BIND_SERVICE" && slices.Contains(baselineCapabilities, capability) {
+					violate(LevelRestricted, CheckDropCapabilities, c.Name, "container adds capability %s", capability)
+				}
+			}
+		}
+		profile := sc.SeccompProfile
+		if profile == nil {
+			profile = pod.SeccompProfile
+		}
+		if profile == nil {
+			violate(LevelRestricted, CheckSeccompProfile, c.Name, "seccompProfile is not set, expected RuntimeDefault or Localhost")
+		}
+
+		// hardening
+		if sc.ReadOnlyRootFilesystem == nil || !*sc.ReadOnlyRootFilesystem {
+			violate(LevelHardening, CheckReadOnlyRootFilesystem, c.Name, "root filesystem is writable")
+		}
+	}
+
+	res.Level = LevelRestricted
+	for _, v := range res.Violations {
+		switch {
+		case v.Level == LevelBaseline:
+			res.Level = LevelPrivileged
+		case v.Level == LevelRestricted && res.Level == LevelRestricted:
+			res.Level = LevelBaseline
+		}
+	}
+	return res
+}
+
+// InspectNamespace reads the Pod Security Admission labels.
+func InspectNamespace(ns *corev1.Namespace) NamespacePosture {
+	posture := NamespacePosture{
+		Name:           ns.Name,
+		Enforce:        ns.Labels[EnforceLabel],
+		EnforceVersion: ns.Labels[EnforceVersionLabel],
+		Audit:          ns.Labels[AuditLabel],
+		Warn:           ns.Labels[WarnLabel],
+	}
+	posture.Enforced = posture.Enforce == LevelBaseline || posture.Enforce == LevelRestricted
+	return posture
+}
+
+// NewSecurityReport orders workloads least secure first and counts them by level.
+func NewSecurityReport(workloads []PodSecurityResult, namespaces []NamespacePosture) SecurityReport {
+	rank := map[string]int{LevelPrivileged: 0, LevelBaseline: 1, LevelRestricted: 2}
+	sort.SliceStable(workloads, func(i, j int) bool {
+		a, b := workloads[i], workloads[j]
+		if a.Level != b.Level {
+			return rank[a.Level] < rank[b.Level]
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	report := SecurityReport{
+		Workloads:  workloads,
+		Namespaces: namespaces,
+		Counts:     map[string]int{LevelPrivileged: 0, LevelBaseline: 0, LevelRestricted: 0},
+	}
+	for _, w := range workloads {
+		report.Counts[w.Level]++
+	}
+	return report
+}
+
+func isUnconfined(p *corev1.SeccompProfile) bool {
+	return p != nil && p.Type == corev1.SeccompProfileTypeUnconfined
+}
+
+// boolOr resolves a container setting that falls back to the pod setting.
+func boolOr(container, pod *bool) bool {
+	if container != nil {
+		return *container
+	}
+	return pod != nil && *pod
+}
+
+// restrictedVolume reports whether the restricted standard allows the volume type.
+func restrictedVolume(v corev1.VolumeSource) bool {
+	return v.ConfigMap != nil || v.CSI != nil || v.DownwardAPI != nil || v.EmptyDir != nil ||
+		v.Ephemeral != nil || v.PersistentVolumeClaim != nil || v.Projected != nil || v.Secret != nil
+}
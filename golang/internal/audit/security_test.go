@@ -0,0 +1,133 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// restrictedSpec mirrors the securityContext of our own Helm chart.
+func restrictedSpec() *corev1.PodSpec {
+	yes, no := true, false
+	return &corev1.PodSpec{
+		SecurityContext: &corev1.PodSecurityContext{
+			RunAsNonRoot:   &yes,
+			SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		},
+		Containers: []corev1.Container{{
+			Name: "app",
+			SecurityContext: &corev1.SecurityContext{
+				AllowPrivilegeEscalation: &no,
+				Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+				ReadOnlyRootFilesystem:   &yes,
+			},
+		}},
+		Volumes: []corev1.Volume{{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+	}
+}
+
+func TestEvaluatePodSecurity(t *testing.T) {
+	ref := health.Ref{Kind: health.KindDeployment, Namespace: "default", Name: "web"}
+	yes := true
+
+	tests := []struct {
+		name   string
+		mutate func(*corev1.PodSpec)
+		level  string
+		checks []string
+	}{
+		{
+			name:   "Restricted",
+			mutate: func(*corev1.PodSpec) {},
+			level:  LevelRestricted,
+			checks: []string{},
+		},
+		{
+			name:   "Writable root filesystem is hardening only",
+			mutate: func(s *corev1.PodSpec) { s.Containers[0].SecurityContext.ReadOnlyRootFilesystem = nil },
+			level:  LevelRestricted,
+			checks: []string{CheckReadOnlyRootFilesystem},
+		},
+		{
+			name:   "Missing seccomp profile",
+			mutate: func(s *corev1.PodSpec) { s.SecurityContext.SeccompProfile = nil },
+			level:  LevelBaseline,
+			checks: []string{CheckSeccompProfile},
+		},
+		{
+			name:   "Container overrides runAsNonRoot",
+			mutate: func(s *corev1.PodSpec) { no := false; s.Containers[0].SecurityContext.RunAsNonRoot = &no },
+			level:  LevelBaseline,
+			checks: []string{CheckRunAsNonRoot},
+		},
+		{
+			name:   "Privileged",
+			mutate: func(s *corev1.PodSpec) { s.Containers[0].SecurityContext.Privileged = &yes },
+			level:  LevelPrivileged,
+			checks: []string{CheckPrivileged},
+		},
+		{
+			name: "Host network and host path",
+			mutate: func(s *corev1.PodSpec) {
+				s.HostNetwork = true
+				s.Volumes = append(s.Volumes, corev1.Volume{Name: "docker", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run/docker.sock"}}})
+			},
+			level:  LevelPrivileged,
+			checks: []string{CheckHostNamespaces, CheckHostPath},
+		},
+		{
+			name: "SYS_ADMIN capability",
+			mutate: func(s *corev1.PodSpec) {
+				s.Containers[0].SecurityContext.Capabilities.Add = []corev1.Capability{"SYS_ADMIN"}
+			},
+			level:  LevelPrivileged,
+			checks: []string{CheckBaselineCapabilities},
+		},
+		{
+			name:   "Bare pod template",
+			mutate: func(s *corev1.PodSpec) { *s = corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}} },
+			level:  LevelBaseline,
+			checks: []string{CheckRunAsNonRoot, CheckAllowPrivilegeEscalation, CheckDropCapabilities, CheckSeccompProfile, CheckReadOnlyRootFilesystem},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := restrictedSpec()
+			tt.mutate(spec)
+
+			res := EvaluatePodSecurity(ref, spec)
+			assert.Equal(t, tt.level, res.Level)
+
+			checks := []string{}
+			for _, v := range res.Violations {
+				checks = append(checks, v.Check)
+			}
+			assert.Equal(t, tt.checks, checks)
+		})
+	}
+}
+
+func TestInspectNamespace(t *testing.T) {
+	enforced := InspectNamespace(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "shop",
+		Labels: map[string]string{EnforceLabel: LevelRestricted, EnforceVersionLabel: "latest"},
+	}})
+	assert.True(t, enforced.Enforced)
+	assert.Equal(t, "latest", enforced.EnforceVersion)
+
+	warnOnly := InspectNamespace(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "legacy",
+		Labels: map[string]string{WarnLabel: LevelBaseline},
+	}})
+	assert.False(t, warnOnly.Enforced)
+
+	privileged := InspectNamespace(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "kube-system",
+		Labels: map[string]string{EnforceLabel: LevelPrivileged},
+	}})
+	assert.False(t, privileged.Enforced)
+}
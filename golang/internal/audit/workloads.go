@@ -0,0 +1,222 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Severities, named after SARIF result levels.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityNote    = "note"
+)
+
+// Workload rule IDs.
+const (
+	RuleMissingReadinessProbe = "missing-readiness-probe"
+	RuleMissingLivenessProbe  = "missing-liveness-probe"
+	RuleMissingCPURequest     = "missing-cpu-request"
+	RuleMissingMemoryRequest  = "missing-memory-request"
+	RuleMissingCPULimit       = "missing-cpu-limit"
+	RuleMissingMemoryLimit    = "missing-memory-limit"
+	RuleLatestTag             = "latest-image-tag"
+	RuleUntaggedImage         = "untagged-image"
+	RuleRegistryNotAllowed    = "registry-not-allowed"
+	RulePullPolicyMismatch    = "image-pull-policy-mismatch"
+)
+
+// Rule describes a check, its severity is the default of its findings.
+type Rule struct {
+	ID          string `json:"id"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+// WorkloadRules are the best-practice checks run on pod templates.
+var WorkloadRules = []Rule{
+	{RuleMissingReadinessProbe, SeverityWarning, "Container has no readiness probe, it receives traffic before it is ready"},
+	{RuleMissingLivenessProbe, SeverityNote, "Container has no liveness probe, a hung process is never restarted"},
+	{RuleMissingCPURequest, SeverityWarning, "Container has no CPU request, the scheduler cannot place it reliably"},
+	{RuleMissingMemoryRequest, SeverityWarning, "Container has no memory request, the scheduler cannot place it reliably"},
+	{RuleMissingCPULimit, SeverityNote, "Container has no CPU limit"},
+	{RuleMissingMemoryLimit, SeverityWarning, "Container has no memory limit, it can exhaust node memory"},
+	{RuleLatestTag, SeverityError, "Image uses the :latest tag, rollouts are not reproducible"},
+	{RuleUntaggedImage, SeverityError, "Image has no tag and resolves to :latest"},
+	{RuleRegistryNotAllowed, SeverityError, "Image is pulled from a registry outside the allowlist"},
+	{RulePullPolicyMismatch, SeverityWarning, "imagePullPolicy does not fit the image reference"},
+}
+
+func findRule(id string) Rule {
+	for _, r := range WorkloadRules {
+		if r.ID == id {
+			return r
+		}
+	}
+	return Rule{ID: id, Severity: SeverityWarning}
+}
+
+// Finding is a rule violation by a single container.
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	health.Ref
+	Container string `json:"container"`
+	Image     string `json:"image,omitempty"`
+	Message   string `json:"message"`
+}
+
+// WorkloadReport is the best-practice audit of workloads.
+type WorkloadReport struct {
+	Findings []Finding      `json:"findings"`
+	Counts   map[string]int `json:"counts"` // by severity
+}
+
+// WorkloadOptions configures the workload audit.
+type WorkloadOptions struct {
+	// Registries ("ghcr.io", "ghcr.io/org") images may come from, empty disables the check
+	AllowedRegistries []string
+}
+
+// AuditPodSpec runs the workload rules on the containers of a pod template.
+// Probes are not checked on init containers, which do not support them.
+func AuditPodSpec(ref health.Ref, spec *corev1.PodSpec, opts WorkloadOptions) []Finding {
+	findings := []Finding{}
+	report := func(rule string, c *corev1.Container, format string, args ...any) {
+		r := findRule(rule)
+		findings = append(findings, Finding{
+			Rule:      r.ID,
+			Severity:  r.Severity,
+			Ref:       ref,
+			Container: c.Name,
+			Image:     c.Image,
+			Message:   fmt.Sprintf(format, args...),
+		})
+	}
+
+	check := func(c *corev1.Container, init bool) {
+		if !init {
+			if c.ReadinessProbe == nil {
+				report(RuleMissingReadinessProbe, c, "container %q has no readiness probe", c.Name)
+			}
+			if c.LivenessProbe == nil {
+				report(RuleMissingLivenessProbe, c, "container %q has no liveness probe", c.Name)
+			}
+		}
+
+		if _, ok := c.Resources.Requests[corev1.ResourceCPU]; !ok {
+			report(RuleMissingCPURequest, c, "container %q has no CPU request", c.Name)
+		}
+		if _, ok := c.Resources.Requests[corev1.ResourceMemory]; !ok {
+			report(RuleMissingMemoryRequest, c, "container %q has no memory request", c.Name)
+		}
+		if _, ok := c.Resources.Limits[corev1.ResourceCPU]; !ok {
+			report(RuleMissingCPULimit, c, "container %q has no CPU limit", c.Name)
+		}
+		if _, ok := c.Resources.Limits[corev1.ResourceMemory]; !ok {
+			report(RuleMissingMemoryLimit, c, "container %q has no memory limit", c.Name)
+		}
+
+		image := ParseImage(c.Image)
+		switch {
+		case image.Digest != "":
+			// pinned, the tag is informational
+		case image.Tag == "":
+			report(RuleUntaggedImage, c, "image %q has no tag", c.Image)
+		case image.Tag == "latest":
+			report(RuleLatestTag, c, "image %q uses the latest tag", c.Image)
+		}
+
+		if len(opts.AllowedRegistries) > 0 && !image.AllowedBy(opts.AllowedRegistries) {
+			report(RuleRegistryNotAllowed, c, "image %q is pulled from %s, allowed: %s", c.Image, image.Registry, strings.Join(opts.AllowedRegistries, ", "))
+		}
+
+		mutable := image.Digest == "" && (image.Tag == "" || image.Tag == "latest")
+		switch {
+		case mutable && c.ImagePullPolicy != "" && c.ImagePullPolicy != corev1.PullAlways:
+			report(RulePullPolicyMismatch, c, "image %q is mutable but imagePullPolicy is %s, nodes may run stale images", c.Image, c.ImagePullPolicy)
+		case image.Digest != "" && c.ImagePullPolicy == corev1.PullAlways:
+			report(RulePullPolicyMismatch, c, "image %q is pinned by digest but imagePullPolicy is Always, pods depend on the registry to start", c.Image)
+		}
+	}
+
+	for i := range spec.InitContainers {
+		check(&spec.InitContainers[i], true)
+	}
+	for i := range spec.Containers {
+		check(&spec.Containers[i], false)
+	}
+	return findings
+}
+
+// NewWorkloadReport orders findings by severity then workload and counts them.
+func NewWorkloadReport(findings []Finding) WorkloadReport {
+	rank := map[string]int{SeverityError: 0, SeverityWarning: 1, SeverityNote: 2}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity != b.Severity {
+			return rank[a.Severity] < rank[b.Severity]
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	report := WorkloadReport{
+		Findings: findings,
+		Counts:   map[string]int{SeverityError: 0, SeverityWarning: 0, SeverityNote: 0},
+	}
+	for _, f := range findings {
+		report.Counts[f.Severity]++
+	}
+	return report
+}
+
+// Image is a parsed container image reference.
+type Image struct {
+	Registry   string // docker.io when omitted
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImage splits "registry/repository:tag@digest" following the Docker reference rules:
+// the first path component is a registry only if it has a dot or a port, or is localhost.
+func ParseImage(ref string) Image {
+	var img Image
+
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref, img.Digest = ref[:i], ref[i+1:]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref, img.Tag = ref[:i], ref[i+1:]
+	}
+
+	img.Registry = "docker.io"
+	if first, rest, ok := strings.Cut(ref, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		img.Registry, ref = first, rest
+	}
+	if img.Registry == "docker.io" && !strings.Contains(ref, "/") {
+		// official images, e.g. nginx is docker.io/library/nginx
+		ref = "library/" + ref
+	}
+	img.Repository = ref
+	return img
+}
+
+// AllowedBy matches the image against registries, or registry path prefixes such as "ghcr.io/org".
+func (img Image) AllowedBy(allowed []string) bool {
+	full := img.Registry + "/" + img.Repository
+	for _, a := range allowed {
+		a = strings.TrimSuffix(a, "/")
+		if a == img.Registry || strings.HasPrefix(full, a+"/") {
+			return true
+		}
+	}
+	return false
+}
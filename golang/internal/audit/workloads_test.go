@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestParseImage(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want Image
+	}{
+		{"nginx", Image{Registry: "docker.io", Repository: "library/nginx"}},
+		{"nginx:1.25", Image{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"}},
+		{"bitnami/redis:latest", Image{Registry: "docker.io", Repository: "bitnami/redis", Tag: "latest"}},
+		{"ghcr.io/org/app:v1", Image{Registry: "ghcr.io", Repository: "org/app", Tag: "v1"}},
+		{"localhost:5000/app", Image{Registry: "localhost:5000", Repository: "app"}},
+		{"ghcr.io/org/app@sha256:abc", Image{Registry: "ghcr.io", Repository: "org/app", Digest: "sha256:abc"}},
+		{"ghcr.io/org/app:v1@sha256:abc", Image{Registry: "ghcr.io", Repository: "org/app", Tag: "v1", Digest: "sha256:abc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseImage(tt.ref))
+		})
+	}
+
+	img := ParseImage("ghcr.io/org/app:v1")
+	assert.True(t, img.AllowedBy([]string{"ghcr.io"}))
+	assert.True(t, img.AllowedBy([]string{"ghcr.io/org/"}))
+	assert.False(t, img.AllowedBy([]string{"ghcr.io/other", "quay.io"}))
+	assert.True(t, ParseImage("nginx").AllowedBy([]string{"docker.io/library"}))
+}
+
+func TestAuditPodSpec(t *testing.T) {
+	ref := health.Ref{Kind: health.KindDeployment, Namespace: "default", Name: "web"}
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("64Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+	}
+	probe := &corev1.Probe{}
+
+	rules := func(findings []Finding) []string {
+		out := []string{}
+		for _, f := range findings {
+			out = append(out, f.Rule)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name      string
+		container corev1.Container
+		init      bool
+		allowed   []string
+		want      []string
+	}{
+		{
+			name:      "Compliant",
+			container: corev1.Container{Name: "app", Image: "ghcr.io/org/app:v1", Resources: resources, ReadinessProbe: probe, LivenessProbe: probe},
+			allowed:   []string{"ghcr.io/org"},
+			want:      []string{},
+		},
+		{
+			name:      "Bare container",
+			container: corev1.Container{Name: "app", Image: "nginx"},
+			want: []string{
+				RuleMissingReadinessProbe, RuleMissingLivenessProbe,
+				RuleMissingCPURequest, RuleMissingMemoryRequest, RuleMissingCPULimit, RuleMissingMemoryLimit,
+				RuleUntaggedImage,
+			},
+		},
+		{
+			name:      "Latest with IfNotPresent from a foreign registry",
+			container: corev1.Container{Name: "app", Image: "quay.io/x/app:latest", ImagePullPolicy: corev1.PullIfNotPresent, Resources: resources, ReadinessProbe: probe, LivenessProbe: probe},
+			allowed:   []string{"ghcr.io"},
+			want:      []string{RuleLatestTag, RuleRegistryNotAllowed, RulePullPolicyMismatch},
+		},
+		{
+			name:      "Digest with Always",
+			container: corev1.Container{Name: "app", Image: "ghcr.io/org/app@sha256:abc", ImagePullPolicy: corev1.PullAlways, Resources: resources, ReadinessProbe: probe, LivenessProbe: probe},
+			want:      []string{RulePullPolicyMismatch},
+		},
+		{
+			name:      "Init containers skip probes",
+			container: corev1.Container{Name: "migrate", Image: "ghcr.io/org/migrate:v1", Resources: resources},
+			init:      true,
+			want:      []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &corev1.PodSpec{}
+			if tt.init {
+				spec.InitContainers = []corev1.Container{tt.container}
+			} else {
+				spec.Containers = []corev1.Container{tt.container}
+			}
+			findings := AuditPodSpec(ref, spec, WorkloadOptions{AllowedRegistries: tt.allowed})
+			assert.Equal(t, tt.want, rules(findings))
+		})
+	}
+}
+
+func TestNewWorkloadReportAndSarif(t *testing.T) {
+	ref := health.Ref{Kind: health.KindDeployment, Namespace: "default", Name: "web"}
+	findings := AuditPodSpec(ref, &corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "nginx"}}}, WorkloadOptions{})
+
+	report := NewWorkloadReport(findings)
+	assert.Equal(t, SeverityError, report.Findings[0].Severity)
+	assert.Equal(t, map[string]int{SeverityError: 1, SeverityWarning: 4, SeverityNote: 2}, report.Counts)
+
+	log := ToSarif(report.Findings)
+	assert.Equal(t, "2.1.0", log.Version)
+	if assert.Len(t, log.Runs, 1) {
+		run := log.Runs[0]
+		assert.Len(t, run.Tool.Driver.Rules, len(WorkloadRules))
+		assert.Len(t, run.Results, len(findings))
+
+		first := run.Results[0]
+		assert.Equal(t, RuleUntaggedImage, first.RuleID)
+		assert.Equal(t, RuleUntaggedImage, run.Tool.Driver.Rules[first.RuleIndex].ID)
+		assert.Equal(t, "default/Deployment/web", first.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	}
+}
@@ -0,0 +1,133 @@
+// Package blocks records network blocks on the labels and annotations of their NetworkPolicies
+// and reads them back, so that the blocks survive restarts without a store of their own.
+package blocks
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Labels and annotations recording a block on both of its policies.
+const (
+	LabelBlockID   = "sre.tyk.io/block-id"
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+	ManagedBy      = "tyk-sre-app"
+
+	AnnotationBlockTargetA   = "sre.tyk.io/block-target-a"
+	AnnotationBlockTargetB   = "sre.tyk.io/block-target-b"
+	AnnotationBlockDirection = "sre.tyk.io/block-direction"
+	AnnotationBlockCreatedBy = "sre.tyk.io/block-created-by"
+	// "true" when the creator was set by a trusted proxy, not by the caller
+	AnnotationBlockCreatedByVerified = "sre.tyk.io/block-created-by-verified"
+	AnnotationBlockReason            = "sre.tyk.io/block-reason"
+	AnnotationBlockCreatedAt         = "sre.tyk.io/block-created-at"
+	// RFC3339, the reaper removes the block after it
+	AnnotationBlockExpiresAt = "sre.tyk.io/block-expires-at"
+)
+
+// PolicyRef names one policy of a block.
+type PolicyRef struct {
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	UID       types.UID `json:"uid,omitempty"`
+}
+
+// WorkloadTarget selects the pods on one side of a block.
+type WorkloadTarget struct {
+	Namespace     string `json:"namespace"`
+	LabelSelector string `json:"label_selector"`
+}
+
+// Block is a pair of block policies, reconstructed from their labels and annotations.
+type Block struct {
+	ID        string         `json:"id"`
+	TargetA   WorkloadTarget `json:"target_a"`
+	TargetB   WorkloadTarget `json:"target_b"`
+	Direction string         `json:"direction"`
+	CreatedBy string         `json:"created_by"`
+	// False when CreatedBy is the created_by the caller sent
+	CreatedByVerified bool      `json:"created_by_verified"`
+	Reason            string    `json:"reason,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	// Unset for blocks that last until deleted
+	ExpiresAt *time.Time  `json:"expires_at,omitempty"`
+	Policies  []PolicyRef `json:"policies"`
+	// False when one of the two policies is missing, e.g. deleted by hand
+	Complete bool `json:"complete"`
+}
+
+// Label records the block on one of its policies.
+func Label(policy *networkingv1.NetworkPolicy, b Block) {
+	targetA, _ := json.Marshal(b.TargetA)
+	targetB, _ := json.Marshal(b.TargetB)
+
+	policy.Labels = map[string]string{
+		LabelBlockID:   b.ID,
+		LabelManagedBy: ManagedBy,
+	}
+	policy.Annotations = map[string]string{
+		AnnotationBlockTargetA:           string(targetA),
+		AnnotationBlockTargetB:           string(targetB),
+		AnnotationBlockDirection:         b.Direction,
+		AnnotationBlockCreatedBy:         b.CreatedBy,
+		AnnotationBlockCreatedByVerified: strconv.FormatBool(b.CreatedByVerified),
+		AnnotationBlockCreatedAt:         b.CreatedAt.Format(time.RFC3339),
+	}
+	if b.Reason != "" {
+		policy.Annotations[AnnotationBlockReason] = b.Reason
+	}
+	if b.ExpiresAt != nil {
+		policy.Annotations[AnnotationBlockExpiresAt] = b.ExpiresAt.Format(time.RFC3339)
+	}
+}
+
+// FromPolicies rebuilds the blocks from their labelled policies, in creation order.
+func FromPolicies(policies []networkingv1.NetworkPolicy) []Block {
+	byID := map[string]*Block{}
+	for _, p := range policies {
+		id := p.Labels[LabelBlockID]
+		if id == "" {
+			continue
+		}
+
+		b, ok := byID[id]
+		if !ok {
+			b = &Block{
+				ID:                id,
+				Direction:         p.Annotations[AnnotationBlockDirection],
+				CreatedBy:         p.Annotations[AnnotationBlockCreatedBy],
+				CreatedByVerified: p.Annotations[AnnotationBlockCreatedByVerified] == "true",
+				Reason:            p.Annotations[AnnotationBlockReason],
+			}
+			_ = json.Unmarshal([]byte(p.Annotations[AnnotationBlockTargetA]), &b.TargetA)
+			_ = json.Unmarshal([]byte(p.Annotations[AnnotationBlockTargetB]), &b.TargetB)
+			b.CreatedAt, _ = time.Parse(time.RFC3339, p.Annotations[AnnotationBlockCreatedAt])
+			if expiresAt, err := time.Parse(time.RFC3339, p.Annotations[AnnotationBlockExpiresAt]); err == nil {
+				b.ExpiresAt = &expiresAt
+			}
+			byID[id] = b
+		}
+		b.Policies = append(b.Policies, PolicyRef{Namespace: p.Namespace, Name: p.Name, UID: p.UID})
+	}
+
+	blocks := make([]Block, 0, len(byID))
+	for _, b := range byID {
+		sort.Slice(b.Policies, func(i, j int) bool {
+			return b.Policies[i].Namespace+"/"+b.Policies[i].Name < b.Policies[j].Namespace+"/"+b.Policies[j].Name
+		})
+		b.Complete = len(b.Policies) == 2
+		blocks = append(blocks, *b)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		if !blocks[i].CreatedAt.Equal(blocks[j].CreatedAt) {
+			return blocks[i].CreatedAt.Before(blocks[j].CreatedAt)
+		}
+		return blocks[i].ID < blocks[j].ID
+	})
+	return blocks
+}
@@ -0,0 +1,55 @@
+package blocks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLabelAndFromPolicies(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	expires := created.Add(time.Hour)
+	older := Block{
+		ID:                "a1",
+		TargetA:           WorkloadTarget{Namespace: "ns-a", LabelSelector: "app=foo"},
+		TargetB:           WorkloadTarget{Namespace: "ns-b", LabelSelector: "app=bar"},
+		Direction:         "both",
+		CreatedBy:         "alice",
+		CreatedByVerified: true,
+		Reason:            "INC-42",
+		CreatedAt:         created,
+		ExpiresAt:         &expires,
+	}
+	newer := Block{ID: "b2", Direction: "ingress", CreatedBy: "bob", CreatedAt: created.Add(time.Minute)}
+
+	policy := func(namespace, name string, b Block) networkingv1.NetworkPolicy {
+		p := networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+		Label(&p, b)
+		return p
+	}
+	policies := []networkingv1.NetworkPolicy{
+		policy("ns-x", "only-side", newer),
+		policy("ns-b", "deny-a", older),
+		policy("ns-a", "deny-b", older),
+		// not a block
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: "default-deny"}},
+	}
+
+	got := FromPolicies(policies)
+	require.Len(t, got, 2)
+
+	want := older
+	want.Policies = []PolicyRef{{Namespace: "ns-a", Name: "deny-b"}, {Namespace: "ns-b", Name: "deny-a"}}
+	want.Complete = true
+	assert.Equal(t, want, got[0])
+
+	assert.Equal(t, "b2", got[1].ID)
+	assert.Nil(t, got[1].ExpiresAt)
+	assert.False(t, got[1].CreatedByVerified)
+	assert.False(t, got[1].Complete, "one of the two policies is missing")
+	assert.Equal(t, ManagedBy, policies[0].Labels[LabelManagedBy])
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// Cluster is one entry of the clusters file.
+type Cluster struct {
+	Name string `json:"name"`
+	// Path of the kubeconfig file, empty for the in-cluster config
+	Kubeconfig string `json:"kubeconfig"`
+	// Context in the kubeconfig, empty for its current context
+	Context string `json:"context"`
+}
+
+// Clusters is the clusters file, e.g.
+//
+//	default: prod-eu
+//	clusters:
+//	  - name: prod-eu
+//	    kubeconfig: /etc/kubeconfigs/prod.yaml
+//	    context: prod-eu
+//	  - name: local
+//	    kubeconfig: ""   # in-cluster
+type Clusters struct {
+	// Serves requests that select no cluster, the first cluster when empty
+	Default  string    `json:"default"`
+	Clusters []Cluster `json:"clusters"`
+}
+
+// LoadClusters reads and validates the clusters file.
+func LoadClusters(path string) (*Clusters, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Clusters{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("clusters file %s: %w", path, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("clusters file %s: %w", path, err)
+	}
+	return c, nil
+}
+
+func (c *Clusters) validate() error {
+	if len(c.Clusters) == 0 {
+		return fmt.Errorf("no clusters")
+	}
+
+	seen := map[string]bool{}
+	for _, cluster := range c.Clusters {
+		if cluster.Name == "" {
+			return fmt.Errorf("cluster without a name")
+		}
+		if err := ValidateClusterName(cluster.Name); err != nil {
+			return err
+		}
+		if seen[cluster.Name] {
+			return fmt.Errorf("duplicate cluster %s", cluster.Name)
+		}
+		seen[cluster.Name] = true
+	}
+
+	if c.Default == "" {
+		c.Default = c.Clusters[0].Name
+	}
+	if !seen[c.Default] {
+		return fmt.Errorf("default cluster %s is not listed", c.Default)
+	}
+	return nil
+}
+
+// ValidateClusterName requires a DNS label, as the name is a path segment of
+// /clusters/{cluster}/ and a component of the history keys.
+func ValidateClusterName(name string) error {
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return fmt.Errorf("invalid cluster name %q: %s", name, strings.Join(errs, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadClusters(t *testing.T) {
+	write := func(content string) string {
+		path := filepath.Join(t.TempDir(), "clusters.yaml")
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	clusters, err := LoadClusters(write(`
+clusters:
+  - name: prod
+    kubeconfig: /etc/kubeconfigs/prod.yaml
+    context: prod-eu
+  - name: local
+`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "prod", clusters.Default)
+		assert.Equal(t, Cluster{Name: "prod", Kubeconfig: "/etc/kubeconfigs/prod.yaml", Context: "prod-eu"}, clusters.Clusters[0])
+	}
+
+	invalid := map[string]string{
+		"empty":           `clusters: []`,
+		"unnamed":         "clusters:\n  - kubeconfig: /x\n",
+		"invalid name":    "clusters:\n  - name: prod/eu\n",
+		"uppercase name":  "clusters:\n  - name: Prod\n",
+		"duplicate":       "clusters:\n  - name: a\n  - name: a\n",
+		"unknown default": "default: b\nclusters:\n  - name: a\n",
+		"unknown field":   "clusters:\n  - name: a\n    kubeContext: x\n",
+	}
+	for name, content := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadClusters(write(content))
+			assert.Error(t, err)
+		})
+	}
+
+	_, err = LoadClusters(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"net"
 
 	"github.com/kelseyhightower/envconfig"
 )
@@ -15,6 +17,57 @@ type Config struct {
 	// Application
 	GracefulTimeout int `default:"10" split_words:"true"` // seconds
 
+	// Multi-cluster, path of the clusters file (see Clusters), empty serves the --kubeconfig cluster only
+	ClustersFile string `split_words:"true"`
+
+	// Informer cache, reads fall back to live API calls when disabled
+	CacheEnabled bool `default:"true" split_words:"true"`
+	CacheResync  int  `default:"300" split_words:"true"` // seconds
+
+	// Health history, transitions sampled into DataDir and kept for HistoryRetentionDays
+	DataDir               string `default:"./data" split_words:"true"`
+	HistoryEnabled        bool   `default:"true" split_words:"true"`
+	HistorySampleInterval int    `default:"30" split_words:"true"` // seconds
+	HistoryRetentionDays  int    `default:"35" split_words:"true"`
+
+	// Notifications on deployment health and API reachability changes, disabled without sinks.
+	// Templates are text/template rendered with a notify.Event, empty uses the sink default
+	NotifyWebhookUrls          []string `split_words:"true"`
+	NotifyWebhookTemplate      string   `split_words:"true"`
+	NotifySlackUrls            []string `split_words:"true"`
+	NotifySlackTemplate        string   `split_words:"true"`
+	NotifyAlertmanagerUrls     []string `split_words:"true"`
+	NotifyAlertmanagerTemplate string   `split_words:"true"`
+	NotifyInterval             int      `default:"30" split_words:"true"`  // seconds
+	NotifyDedupWindow          int      `default:"300" split_words:"true"` // seconds
+	NotifyRetries              int      `default:"3" split_words:"true"`
+	NotifyRetryBackoff         int      `default:"1" split_words:"true"` // seconds, doubled on each retry
+
+	// Upper bound of ?wait=true on deployment rollout actions
+	RolloutWaitTimeout int `default:"300" split_words:"true"` // seconds
+
+	// Scale guardrails by namespace ("shop:2,*:0"), "*" applies to the rest
+	ScaleMinReplicas         map[string]int `split_words:"true"`
+	ScaleMaxReplicas         map[string]int `split_words:"true"`
+	ScaleProtectedNamespaces []string       `split_words:"true"` // never scaled to zero
+
+	// Network blocks, pod CIDRs of the cluster ("10.244.0.0/16"). Egress blocks allow every
+	// address outside them and are rejected while it is empty
+	NetworkPodCidrs []string `split_words:"true"`
+	// Block dry-runs warn when a target selector matches more pods, 0 disables the warning
+	NetworkBlockPodWarnThreshold int `default:"50" split_words:"true"`
+	// Time between passes removing expired blocks
+	NetworkBlockReapInterval int `default:"30" split_words:"true"` // seconds
+	// Source addresses ("10.0.0.0/8") of the authenticating proxies whose X-Forwarded-User
+	// records the block creator, empty ignores the header
+	NetworkTrustedProxyCidrs []string `split_words:"true"`
+
+	// Workload audit, registries ("ghcr.io", "ghcr.io/org") images may come from (empty skips the check)
+	AuditAllowedRegistries []string `split_words:"true"`
+
+	// Metrics, namespaces exported with per-deployment series (empty allows all)
+	MetricsNamespaceAllow []string `split_words:"true"`
+	MetricsNamespaceDeny  []string `split_words:"true"`
 }
 
 func Load() *Config {
@@ -24,6 +77,40 @@ func Load() *Config {
 	if err != nil {
 		log.Fatal(err.Error())
 	}
+	if err := c.validate(); err != nil {
+		log.Fatal(err.Error())
+	}
 
 	return c
 }
+
+// validate rejects values envconfig accepts but the app cannot run with.
+func (c *Config) validate() error {
+	for _, cidr := range c.NetworkPodCidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("NETWORK_POD_CIDRS: %w", err)
+		}
+	}
+	for _, cidr := range c.NetworkTrustedProxyCidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("NETWORK_TRUSTED_PROXY_CIDRS: %w", err)
+		}
+	}
+
+	// background loops tick at these, time.NewTicker panics on zero or less
+	intervals := []struct {
+		name  string
+		value int
+	}{
+		{"HISTORY_SAMPLE_INTERVAL", c.HistorySampleInterval},
+		{"NOTIFY_INTERVAL", c.NotifyInterval},
+		{"NETWORK_BLOCK_REAP_INTERVAL", c.NetworkBlockReapInterval},
+	}
+	for _, i := range intervals {
+		if i.value <= 0 {
+			return fmt.Errorf("%s: must be a positive number of seconds, got %d", i.name, i.value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		return &Config{HistorySampleInterval: 30, NotifyInterval: 30, NetworkBlockReapInterval: 30, NetworkPodCidrs: []string{"10.244.0.0/16"}}
+	}
+	assert.NoError(t, valid().validate())
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		err    string
+	}{
+		{"Invalid pod CIDR", func(c *Config) { c.NetworkPodCidrs = []string{"10.244.0.0"} }, "NETWORK_POD_CIDRS"},
+		{"Invalid trusted proxy CIDR", func(c *Config) { c.NetworkTrustedProxyCidrs = []string{"proxy"} }, "NETWORK_TRUSTED_PROXY_CIDRS"},
+		{"Zero sample interval", func(c *Config) { c.HistorySampleInterval = 0 }, "HISTORY_SAMPLE_INTERVAL"},
+		{"Negative notify interval", func(c *Config) { c.NotifyInterval = -1 }, "NOTIFY_INTERVAL"},
+		{"Zero reap interval", func(c *Config) { c.NetworkBlockReapInterval = 0 }, "NETWORK_BLOCK_REAP_INTERVAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.modify(c)
+			err := c.validate()
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.err)
+			}
+		})
+	}
+}
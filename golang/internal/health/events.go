@@ -0,0 +1,97 @@
+package health
+
+import (
+	"slices"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EventSummary groups the events of one reason, e.g. FailedScheduling or BackOff.
+type EventSummary struct {
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"` // of the most recent occurrence
+	Count   int32  `json:"count"`
+	// Objects are the involved "Kind/name", in order of first appearance
+	Objects        []string    `json:"objects"`
+	FirstTimestamp metav1.Time `json:"first_timestamp"`
+	LastTimestamp  metav1.Time `json:"last_timestamp"`
+}
+
+// SummarizeEvents deduplicates events by type and reason, most recent first.
+// eventType filters by type (e.g. Warning), empty keeps all.
+func SummarizeEvents(events []corev1.Event, eventType string) []EventSummary {
+	byReason := map[string]*EventSummary{}
+	summaries := []*EventSummary{}
+
+	for _, e := range events {
+		if eventType != "" && e.Type != eventType {
+			continue
+		}
+
+		first, last := eventTimes(&e)
+		count := e.Count
+		if count == 0 {
+			count = 1
+		}
+		object := e.InvolvedObject.Kind + "/" + e.InvolvedObject.Name
+
+		key := e.Type + "/" + e.Reason
+		s, ok := byReason[key]
+		if !ok {
+			s = &EventSummary{
+				Type:           e.Type,
+				Reason:         e.Reason,
+				Message:        e.Message,
+				Objects:        []string{},
+				FirstTimestamp: first,
+				LastTimestamp:  last,
+			}
+			byReason[key] = s
+			summaries = append(summaries, s)
+		}
+
+		s.Count += count
+		if !slices.Contains(s.Objects, object) {
+			s.Objects = append(s.Objects, object)
+		}
+		if first.Before(&s.FirstTimestamp) {
+			s.FirstTimestamp = first
+		}
+		if s.LastTimestamp.Before(&last) {
+			s.LastTimestamp = last
+			s.Message = e.Message
+		}
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[j].LastTimestamp.Before(&summaries[i].LastTimestamp)
+	})
+
+	result := make([]EventSummary, 0, len(summaries))
+	for _, s := range summaries {
+		result = append(result, *s)
+	}
+	return result
+}
+
+// eventTimes falls back to EventTime and the creation time,
+// events.k8s.io clients do not set the legacy timestamps.
+func eventTimes(e *corev1.Event) (first, last metav1.Time) {
+	first, last = e.FirstTimestamp, e.LastTimestamp
+	if last.IsZero() && !e.EventTime.IsZero() {
+		last = metav1.NewTime(e.EventTime.Time)
+	}
+	if e.Series != nil && !e.Series.LastObservedTime.IsZero() {
+		last = metav1.NewTime(e.Series.LastObservedTime.Time)
+	}
+	if last.IsZero() {
+		last = e.CreationTimestamp
+	}
+	if first.IsZero() {
+		first = last
+	}
+	return first, last
+}
@@ -0,0 +1,58 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSummarizeEvents(t *testing.T) {
+	now := time.Now()
+	at := func(ago time.Duration) metav1.Time { return metav1.NewTime(now.Add(-ago).Truncate(time.Second)) }
+
+	events := []corev1.Event{
+		{
+			Type: corev1.EventTypeWarning, Reason: "BackOff", Message: "old message", Count: 3,
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-1"},
+			FirstTimestamp: at(time.Hour), LastTimestamp: at(10 * time.Minute),
+		},
+		{
+			Type: corev1.EventTypeWarning, Reason: "BackOff", Message: "new message", Count: 2,
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-2"},
+			FirstTimestamp: at(30 * time.Minute), LastTimestamp: at(time.Minute),
+		},
+		{
+			// events.k8s.io style, no legacy timestamps
+			Type: corev1.EventTypeWarning, Reason: "FailedScheduling", Message: "0/3 nodes are available",
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-3"},
+			EventTime:      metav1.NewMicroTime(now.Add(-5 * time.Minute)),
+		},
+		{
+			Type: corev1.EventTypeNormal, Reason: "ScalingReplicaSet", Count: 1,
+			InvolvedObject: corev1.ObjectReference{Kind: "Deployment", Name: "web"},
+			LastTimestamp:  at(0),
+		},
+	}
+
+	got := SummarizeEvents(events, corev1.EventTypeWarning)
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, "BackOff", got[0].Reason)
+		assert.Equal(t, int32(5), got[0].Count)
+		assert.Equal(t, "new message", got[0].Message)
+		assert.Equal(t, []string{"Pod/web-1", "Pod/web-2"}, got[0].Objects)
+		assert.Equal(t, at(time.Hour), got[0].FirstTimestamp)
+
+		assert.Equal(t, "FailedScheduling", got[1].Reason)
+		assert.Equal(t, int32(1), got[1].Count)
+	}
+
+	all := SummarizeEvents(events, "")
+	if assert.Len(t, all, 3) {
+		assert.Equal(t, "ScalingReplicaSet", all[0].Reason)
+	}
+
+	assert.Empty(t, SummarizeEvents(nil, corev1.EventTypeWarning))
+}
@@ -0,0 +1,129 @@
+package health
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Check names reported in Reason.Check.
+const (
+	CheckReadyReplicas       = "ready_replicas"
+	CheckUpdatedReplicas     = "updated_replicas"
+	CheckUnavailableReplicas = "unavailable_replicas"
+	CheckAvailable           = "available_condition"
+	CheckProgressing         = "progressing_condition"
+)
+
+// Reason describes a single failing health check.
+type Reason struct {
+	Check    string `json:"check"`
+	Observed string `json:"observed"`
+	Expected string `json:"expected"`
+	Message  string `json:"message,omitempty"`
+}
+
+// Result is the outcome of evaluating a workload, Healthy when no check failed.
+// Degraded workloads are up but short on capacity, see EvaluateDeploymentHPA.
+type Result struct {
+	Healthy  bool     `json:"healthy"`
+	Degraded bool     `json:"degraded,omitempty"`
+	Reasons  []Reason `json:"reasons"`
+	// Capacity problems, kept apart from Reasons as they do not fail health
+	DegradedReasons []Reason `json:"degraded_reasons,omitempty"`
+}
+
+// add records a failing check.
+func (r *Result) add(check, observed, expected, message string) {
+	r.Reasons = append(r.Reasons, Reason{
+		Check:    check,
+		Observed: observed,
+		Expected: expected,
+		Message:  message,
+	})
+}
+
+// degrade records a capacity problem that does not fail the health checks.
+func (r *Result) degrade(check, observed, expected, message string) {
+	r.Degraded = true
+	r.DegradedReasons = append(r.DegradedReasons, Reason{
+		Check:    check,
+		Observed: observed,
+		Expected: expected,
+		Message:  message,
+	})
+}
+
+// EvaluateDeployment checks a Deployment against the health rules:
+// 1. ReadyReplicas must match Desired Replicas
+// 2. UpdatedReplicas must match Desired Replicas (Rolling update finished)
+// 3. UnavailableReplicas must be 0
+// 4. Status.conditions type=Available must be True
+// 5. Status.conditions type=Progressing must be True (not ProgressDeadlineExceeded)
+func EvaluateDeployment(d *appsv1.Deployment) Result {
+	res := Result{Reasons: []Reason{}}
+
+	desired := int32(0)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+
+	if d.Status.ReadyReplicas != desired {
+		res.add(CheckReadyReplicas, itoa(d.Status.ReadyReplicas), itoa(desired), "")
+	}
+	if d.Status.UpdatedReplicas != desired {
+		res.add(CheckUpdatedReplicas, itoa(d.Status.UpdatedReplicas), itoa(desired), "")
+	}
+	if d.Status.UnavailableReplicas != 0 {
+		res.add(CheckUnavailableReplicas, itoa(d.Status.UnavailableReplicas), "0", "")
+	}
+
+	checkCondition(&res, CheckAvailable, findDeploymentCondition(d, appsv1.DeploymentAvailable))
+	checkCondition(&res, CheckProgressing, findDeploymentCondition(d, appsv1.DeploymentProgressing))
+
+	res.Healthy = len(res.Reasons) == 0
+	return res
+}
+
+// condition is the subset of a workload condition the evaluator cares about.
+type condition struct {
+	Status  corev1.ConditionStatus
+	Reason  string
+	Message string
+}
+
+func findDeploymentCondition(d *appsv1.Deployment, t appsv1.DeploymentConditionType) *condition {
+	for _, c := range d.Status.Conditions {
+		if c.Type == t {
+			return &condition{Status: c.Status, Reason: c.Reason, Message: c.Message}
+		}
+	}
+	return nil
+}
+
+// checkCondition requires the condition to be present with status True.
+func checkCondition(res *Result, check string, c *condition) {
+	if c == nil {
+		res.add(check, "Missing", string(corev1.ConditionTrue), "condition not reported")
+		return
+	}
+	if c.Status == corev1.ConditionTrue {
+		return
+	}
+
+	observed := string(c.Status)
+	if c.Reason != "" {
+		// e.g. False/ProgressDeadlineExceeded
+		observed += "/" + c.Reason
+	}
+	res.add(check, observed, string(corev1.ConditionTrue), c.Message)
+}
+
+func itoa(i int32) string {
+	return fmt.Sprintf("%d", i)
+}
+
+func itoa64(i int64) string {
+	return fmt.Sprintf("%d", i)
+}
@@ -0,0 +1,114 @@
+package health
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func healthyDeployment() *appsv1.Deployment {
+	replicas := int32(3)
+	return &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			ReadyReplicas:   3,
+			UpdatedReplicas: 3,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+func TestEvaluateDeployment_Healthy(t *testing.T) {
+	res := EvaluateDeployment(healthyDeployment())
+
+	assert.True(t, res.Healthy)
+	assert.NotNil(t, res.Reasons)
+	assert.Empty(t, res.Reasons)
+}
+
+func TestEvaluateDeployment_Reasons(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(d *appsv1.Deployment)
+		expected []Reason
+	}{
+		{
+			name: "Ready and updated behind desired",
+			mutate: func(d *appsv1.Deployment) {
+				d.Status.ReadyReplicas = 1
+				d.Status.UpdatedReplicas = 2
+			},
+			expected: []Reason{
+				{Check: CheckReadyReplicas, Observed: "1", Expected: "3"},
+				{Check: CheckUpdatedReplicas, Observed: "2", Expected: "3"},
+			},
+		},
+		{
+			name: "Unavailable replicas",
+			mutate: func(d *appsv1.Deployment) {
+				d.Status.UnavailableReplicas = 2
+			},
+			expected: []Reason{
+				{Check: CheckUnavailableReplicas, Observed: "2", Expected: "0"},
+			},
+		},
+		{
+			name: "Available false",
+			mutate: func(d *appsv1.Deployment) {
+				d.Status.Conditions[0].Status = corev1.ConditionFalse
+				d.Status.Conditions[0].Reason = "MinimumReplicasUnavailable"
+				d.Status.Conditions[0].Message = "Deployment does not have minimum availability."
+			},
+			expected: []Reason{
+				{
+					Check:    CheckAvailable,
+					Observed: "False/MinimumReplicasUnavailable",
+					Expected: "True",
+					Message:  "Deployment does not have minimum availability.",
+				},
+			},
+		},
+		{
+			name: "Progress deadline exceeded",
+			mutate: func(d *appsv1.Deployment) {
+				d.Status.Conditions[1].Status = corev1.ConditionFalse
+				d.Status.Conditions[1].Reason = "ProgressDeadlineExceeded"
+				d.Status.Conditions[1].Message = `ReplicaSet "web-5d9c" has timed out progressing.`
+			},
+			expected: []Reason{
+				{
+					Check:    CheckProgressing,
+					Observed: "False/ProgressDeadlineExceeded",
+					Expected: "True",
+					Message:  `ReplicaSet "web-5d9c" has timed out progressing.`,
+				},
+			},
+		},
+		{
+			name: "Missing conditions",
+			mutate: func(d *appsv1.Deployment) {
+				d.Status.Conditions = nil
+			},
+			expected: []Reason{
+				{Check: CheckAvailable, Observed: "Missing", Expected: "True", Message: "condition not reported"},
+				{Check: CheckProgressing, Observed: "Missing", Expected: "True", Message: "condition not reported"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := healthyDeployment()
+			tt.mutate(d)
+
+			res := EvaluateDeployment(d)
+			assert.False(t, res.Healthy)
+			assert.Equal(t, tt.expected, res.Reasons)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package health
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+)
+
+// Check names of the autoscaler aware evaluation.
+const (
+	CheckHPADesiredReplicas = "hpa_desired_replicas"
+	CheckScalingLimited     = "scaling_limited"
+)
+
+// HPAStatus is the view of the HorizontalPodAutoscaler targeting a workload.
+type HPAStatus struct {
+	Name            string `json:"name"`
+	CurrentReplicas int32  `json:"current_replicas"`
+	DesiredReplicas int32  `json:"desired_replicas"`
+	MinReplicas     int32  `json:"min_replicas"`
+	MaxReplicas     int32  `json:"max_replicas"`
+	// Pinned at MaxReplicas with a metric above its target
+	ScalingLimited bool `json:"scaling_limited"`
+	// e.g. "cpu: 95% > 80%"
+	ExceededMetrics []string `json:"exceeded_metrics,omitempty"`
+}
+
+// InspectHPA summarizes replica bounds and metrics above target.
+func InspectHPA(hpa *autoscalingv2.HorizontalPodAutoscaler) HPAStatus {
+	status := HPAStatus{
+		Name:            hpa.Name,
+		CurrentReplicas: hpa.Status.CurrentReplicas,
+		DesiredReplicas: hpa.Status.DesiredReplicas,
+		MinReplicas:     1,
+		MaxReplicas:     hpa.Spec.MaxReplicas,
+	}
+	if hpa.Spec.MinReplicas != nil {
+		status.MinReplicas = *hpa.Spec.MinReplicas
+	}
+
+	for _, current := range hpa.Status.CurrentMetrics {
+		for _, spec := range hpa.Spec.Metrics {
+			if exceeded, ok := metricExceeded(spec, current); ok {
+				status.ExceededMetrics = append(status.ExceededMetrics, exceeded)
+			}
+		}
+	}
+
+	status.ScalingLimited = status.MaxReplicas > 0 &&
+		status.CurrentReplicas >= status.MaxReplicas &&
+		len(status.ExceededMetrics) > 0
+	return status
+}
+
+// EvaluateDeploymentHPA extends EvaluateDeployment with the autoscaler view:
+// 1. ReadyReplicas must reach the HPA DesiredReplicas while it scales up
+// 2. The HPA must not be pinned at MaxReplicas with a metric above its target
+// Both are capacity problems, they mark the result Degraded and leave Healthy as is.
+func EvaluateDeploymentHPA(d *appsv1.Deployment, hpa *autoscalingv2.HorizontalPodAutoscaler) (Result, HPAStatus) {
+	res := EvaluateDeployment(d)
+	status := InspectHPA(hpa)
+
+	if status.DesiredReplicas > d.Status.ReadyReplicas {
+		res.degrade(CheckHPADesiredReplicas, itoa(d.Status.ReadyReplicas), itoa(status.DesiredReplicas), "autoscaler scale up in progress")
+	}
+	if status.ScalingLimited {
+		res.degrade(CheckScalingLimited, itoa(status.CurrentReplicas), "<"+itoa(status.MaxReplicas),
+			"pinned at maxReplicas: "+strings.Join(status.ExceededMetrics, ", "))
+	}
+
+	return res, status
+}
+
+// FindHPA returns the HorizontalPodAutoscaler targeting kind/name in namespace, nil if none.
+func FindHPA(hpas []autoscalingv2.HorizontalPodAutoscaler, namespace, kind, name string) *autoscalingv2.HorizontalPodAutoscaler {
+	for i := range hpas {
+		ref := hpas[i].Spec.ScaleTargetRef
+		if hpas[i].Namespace == namespace && ref.Kind == kind && ref.Name == name {
+			return &hpas[i]
+		}
+	}
+	return nil
+}
+
+// EvaluateDeploymentAutoscaled evaluates d with EvaluateDeploymentHPA when one of hpas
+// targets it, else with EvaluateDeployment and a nil HPAStatus. An empty hpas, e.g. when
+// they cannot be listed, only loses the capacity checks as they never change Healthy.
+func EvaluateDeploymentAutoscaled(d *appsv1.Deployment, hpas []autoscalingv2.HorizontalPodAutoscaler) (Result, *HPAStatus) {
+	hpa := FindHPA(hpas, d.Namespace, KindDeployment, d.Name)
+	if hpa == nil {
+		return EvaluateDeployment(d), nil
+	}
+	res, status := EvaluateDeploymentHPA(d, hpa)
+	return res, &status
+}
+
+// metricExceeded compares a current metric with its spec, ok is false
+// when they describe different metrics or current is within target.
+func metricExceeded(spec autoscalingv2.MetricSpec, current autoscalingv2.MetricStatus) (string, bool) {
+	if spec.Type != current.Type {
+		return "", false
+	}
+
+	var name string
+	var target autoscalingv2.MetricTarget
+	var value autoscalingv2.MetricValueStatus
+
+	switch spec.Type {
+	case autoscalingv2.ResourceMetricSourceType:
+		if spec.Resource == nil || current.Resource == nil || spec.Resource.Name != current.Resource.Name {
+			return "", false
+		}
+		name, target, value = string(spec.Resource.Name), spec.Resource.Target, current.Resource.Current
+	case autoscalingv2.ContainerResourceMetricSourceType:
+		if spec.ContainerResource == nil || current.ContainerResource == nil ||
+			spec.ContainerResource.Name != current.ContainerResource.Name ||
+			spec.ContainerResource.Container != current.ContainerResource.Container {
+			return "", false
+		}
+		name = spec.ContainerResource.Container + "/" + string(spec.ContainerResource.Name)
+		target, value = spec.ContainerResource.Target, current.ContainerResource.Current
+	case autoscalingv2.PodsMetricSourceType:
+		if spec.Pods == nil || current.Pods == nil || spec.Pods.Metric.Name != current.Pods.Metric.Name {
+			return "", false
+		}
+		name, target, value = spec.Pods.Metric.Name, spec.Pods.Target, current.Pods.Current
+	case autoscalingv2.ObjectMetricSourceType:
+		if spec.Object == nil || current.Object == nil || spec.Object.Metric.Name != current.Object.Metric.Name {
+			return "", false
+		}
+		name, target, value = spec.Object.Metric.Name, spec.Object.Target, current.Object.Current
+	case autoscalingv2.ExternalMetricSourceType:
+		if spec.External == nil || current.External == nil || spec.External.Metric.Name != current.External.Metric.Name {
+			return "", false
+		}
+		name, target, value = spec.External.Metric.Name, spec.External.Target, current.External.Current
+	default:
+		return "", false
+	}
+
+	switch {
+	case target.AverageUtilization != nil && value.AverageUtilization != nil:
+		if *value.AverageUtilization > *target.AverageUtilization {
+			return fmt.Sprintf("%s: %d%% > %d%%", name, *value.AverageUtilization, *target.AverageUtilization), true
+		}
+	case target.AverageValue != nil && value.AverageValue != nil:
+		if value.AverageValue.Cmp(*target.AverageValue) > 0 {
+			return fmt.Sprintf("%s: %s > %s", name, value.AverageValue, target.AverageValue), true
+		}
+	case target.Value != nil && value.Value != nil:
+		if value.Value.Cmp(*target.Value) > 0 {
+			return fmt.Sprintf("%s: %s > %s", name, value.Value, target.Value), true
+		}
+	}
+	return "", false
+}
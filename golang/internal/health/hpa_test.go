@@ -0,0 +1,170 @@
+package health
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func cpuHPA(current, desired, max, utilization int32) *autoscalingv2.HorizontalPodAutoscaler {
+	target := int32(80)
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			MaxReplicas: max,
+			Metrics: []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name:   corev1.ResourceCPU,
+					Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: &target},
+				},
+			}},
+		},
+		Status: autoscalingv2.HorizontalPodAutoscalerStatus{
+			CurrentReplicas: current,
+			DesiredReplicas: desired,
+			CurrentMetrics: []autoscalingv2.MetricStatus{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricStatus{
+					Name:    corev1.ResourceCPU,
+					Current: autoscalingv2.MetricValueStatus{AverageUtilization: &utilization},
+				},
+			}},
+		},
+	}
+}
+
+func readyDeployment(replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			ReadyReplicas:   replicas,
+			UpdatedReplicas: replicas,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+func TestEvaluateDeploymentHPA(t *testing.T) {
+	tests := []struct {
+		name     string
+		ready    int32
+		hpa      *autoscalingv2.HorizontalPodAutoscaler
+		degraded bool
+		checks   []string
+		limited  bool
+	}{
+		{
+			name:   "Within bounds",
+			ready:  3,
+			hpa:    cpuHPA(3, 3, 10, 60),
+			checks: []string{},
+		},
+		{
+			name:     "Pinned at max with CPU saturated",
+			ready:    10,
+			hpa:      cpuHPA(10, 10, 10, 95),
+			degraded: true,
+			checks:   []string{CheckScalingLimited},
+			limited:  true,
+		},
+		{
+			name:   "At max but below target",
+			ready:  10,
+			hpa:    cpuHPA(10, 10, 10, 50),
+			checks: []string{},
+		},
+		{
+			name:     "Scaling up",
+			ready:    3,
+			hpa:      cpuHPA(3, 6, 10, 120),
+			degraded: true,
+			checks:   []string{CheckHPADesiredReplicas},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, status := EvaluateDeploymentHPA(readyDeployment(tt.ready), tt.hpa)
+
+			assert.True(t, res.Healthy, "degraded capacity does not fail health")
+			assert.Equal(t, tt.degraded, res.Degraded)
+			assert.Equal(t, tt.limited, status.ScalingLimited)
+
+			assert.Empty(t, res.Reasons, "capacity problems are not failing checks")
+			checks := []string{}
+			for _, r := range res.DegradedReasons {
+				checks = append(checks, r.Check)
+			}
+			assert.Equal(t, tt.checks, checks)
+		})
+	}
+}
+
+func TestEvaluateDeploymentAutoscaled(t *testing.T) {
+	d := readyDeployment(3)
+	d.Namespace, d.Name = "shop", "web"
+
+	other := cpuHPA(3, 6, 10, 120)
+	other.ObjectMeta = metav1.ObjectMeta{Name: "web", Namespace: "other"}
+	other.Spec.ScaleTargetRef = autoscalingv2.CrossVersionObjectReference{Kind: KindDeployment, Name: "web"}
+
+	res, status := EvaluateDeploymentAutoscaled(d, []autoscalingv2.HorizontalPodAutoscaler{*other})
+	assert.Nil(t, status, "an HPA in another namespace does not target d")
+	assert.False(t, res.Degraded)
+
+	hpa := other.DeepCopy()
+	hpa.Namespace = "shop"
+	res, status = EvaluateDeploymentAutoscaled(d, []autoscalingv2.HorizontalPodAutoscaler{*other, *hpa})
+	if assert.NotNil(t, status) {
+		assert.Equal(t, int32(6), status.DesiredReplicas)
+	}
+	assert.True(t, res.Healthy)
+	assert.True(t, res.Degraded)
+
+	res, status = EvaluateDeploymentAutoscaled(d, nil)
+	assert.Nil(t, status)
+	assert.True(t, res.Healthy)
+}
+
+func TestInspectHPA_AverageValue(t *testing.T) {
+	target := resource.MustParse("100")
+	current := resource.MustParse("250")
+	min := int32(2)
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			MinReplicas: &min,
+			MaxReplicas: 4,
+			Metrics: []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.PodsMetricSourceType,
+				Pods: &autoscalingv2.PodsMetricSource{
+					Metric: autoscalingv2.MetricIdentifier{Name: "requests_per_second"},
+					Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: &target},
+				},
+			}},
+		},
+		Status: autoscalingv2.HorizontalPodAutoscalerStatus{
+			CurrentReplicas: 4,
+			DesiredReplicas: 4,
+			CurrentMetrics: []autoscalingv2.MetricStatus{{
+				Type: autoscalingv2.PodsMetricSourceType,
+				Pods: &autoscalingv2.PodsMetricStatus{
+					Metric:  autoscalingv2.MetricIdentifier{Name: "requests_per_second"},
+					Current: autoscalingv2.MetricValueStatus{AverageValue: &current},
+				},
+			}},
+		},
+	}
+
+	status := InspectHPA(hpa)
+	assert.Equal(t, int32(2), status.MinReplicas)
+	assert.True(t, status.ScalingLimited)
+	assert.Equal(t, []string{"requests_per_second: 250 > 100"}, status.ExceededMetrics)
+}
@@ -0,0 +1,109 @@
+package health
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Container states reported in ContainerReport.State.
+const (
+	ContainerRunning    = "running"
+	ContainerWaiting    = "waiting"
+	ContainerTerminated = "terminated"
+	ContainerUnknown    = "unknown"
+)
+
+// ContainerReport is the diagnostic view of a single container.
+type ContainerReport struct {
+	Name         string `json:"name"`
+	Init         bool   `json:"init,omitempty"`
+	Image        string `json:"image"`
+	Ready        bool   `json:"ready"`
+	State        string `json:"state"`
+	Reason       string `json:"reason,omitempty"` // e.g. CrashLoopBackOff, ImagePullBackOff
+	Message      string `json:"message,omitempty"`
+	RestartCount int32  `json:"restart_count"`
+
+	// Last termination, e.g. OOMKilled
+	LastTerminationReason   string `json:"last_termination_reason,omitempty"`
+	LastTerminationExitCode *int32 `json:"last_termination_exit_code,omitempty"`
+}
+
+// PodReport is the diagnostic view of a pod behind a workload.
+type PodReport struct {
+	Name       string            `json:"name"`
+	Namespace  string            `json:"namespace"`
+	Owner      string            `json:"owner,omitempty"`
+	Node       string            `json:"node,omitempty"`
+	Phase      corev1.PodPhase   `json:"phase"`
+	Reason     string            `json:"reason,omitempty"` // e.g. Evicted
+	Ready      bool              `json:"ready"`
+	Restarts   int32             `json:"restarts"`
+	Containers []ContainerReport `json:"containers"`
+}
+
+// InspectPod summarizes pod and container states for drill-down.
+func InspectPod(p *corev1.Pod) PodReport {
+	report := PodReport{
+		Name:       p.Name,
+		Namespace:  p.Namespace,
+		Node:       p.Spec.NodeName,
+		Phase:      p.Status.Phase,
+		Reason:     p.Status.Reason,
+		Containers: []ContainerReport{},
+	}
+
+	if owner := metav1.GetControllerOf(p); owner != nil {
+		report.Owner = owner.Name
+	}
+
+	for _, c := range p.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			report.Ready = c.Status == corev1.ConditionTrue
+		}
+	}
+
+	for _, cs := range p.Status.InitContainerStatuses {
+		cr := inspectContainer(cs)
+		cr.Init = true
+		report.Containers = append(report.Containers, cr)
+	}
+	for _, cs := range p.Status.ContainerStatuses {
+		cr := inspectContainer(cs)
+		report.Restarts += cr.RestartCount
+		report.Containers = append(report.Containers, cr)
+	}
+
+	return report
+}
+
+func inspectContainer(cs corev1.ContainerStatus) ContainerReport {
+	cr := ContainerReport{
+		Name:         cs.Name,
+		Image:        cs.Image,
+		Ready:        cs.Ready,
+		State:        ContainerUnknown,
+		RestartCount: cs.RestartCount,
+	}
+
+	switch {
+	case cs.State.Waiting != nil:
+		cr.State = ContainerWaiting
+		cr.Reason = cs.State.Waiting.Reason
+		cr.Message = cs.State.Waiting.Message
+	case cs.State.Terminated != nil:
+		cr.State = ContainerTerminated
+		cr.Reason = cs.State.Terminated.Reason
+		cr.Message = cs.State.Terminated.Message
+	case cs.State.Running != nil:
+		cr.State = ContainerRunning
+	}
+
+	if t := cs.LastTerminationState.Terminated; t != nil {
+		exitCode := t.ExitCode
+		cr.LastTerminationReason = t.Reason
+		cr.LastTerminationExitCode = &exitCode
+	}
+
+	return cr
+}
@@ -0,0 +1,77 @@
+package health
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInspectPod(t *testing.T) {
+	controller := true
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web-5d9c-abcde",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: "web-5d9c", Controller: &controller},
+			},
+		},
+		Spec: corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionFalse},
+			},
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  "migrate",
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}},
+				},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "app",
+					RestartCount: 5,
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 5m0s"},
+					},
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+					},
+				},
+				{
+					Name:         "sidecar",
+					Ready:        true,
+					RestartCount: 1,
+					State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				},
+			},
+		},
+	}
+
+	report := InspectPod(pod)
+
+	assert.Equal(t, "web-5d9c", report.Owner)
+	assert.Equal(t, "node-1", report.Node)
+	assert.Equal(t, corev1.PodRunning, report.Phase)
+	assert.False(t, report.Ready)
+	assert.Equal(t, int32(6), report.Restarts)
+	assert.Len(t, report.Containers, 3)
+
+	initC := report.Containers[0]
+	assert.True(t, initC.Init)
+	assert.Equal(t, ContainerTerminated, initC.State)
+	assert.Equal(t, "Completed", initC.Reason)
+
+	app := report.Containers[1]
+	assert.Equal(t, ContainerWaiting, app.State)
+	assert.Equal(t, "CrashLoopBackOff", app.Reason)
+	assert.Equal(t, "OOMKilled", app.LastTerminationReason)
+	assert.Equal(t, int32(137), *app.LastTerminationExitCode)
+
+	sidecar := report.Containers[2]
+	assert.Equal(t, ContainerRunning, sidecar.State)
+	assert.Nil(t, sidecar.LastTerminationExitCode)
+}
@@ -0,0 +1,54 @@
+package health
+
+// Counts is a total/healthy/unhealthy rollup.
+type Counts struct {
+	Total     int `json:"total"`
+	Healthy   int `json:"healthy"`
+	Unhealthy int `json:"unhealthy"`
+}
+
+func (c *Counts) add(healthy bool) {
+	c.Total++
+	if healthy {
+		c.Healthy++
+	} else {
+		c.Unhealthy++
+	}
+}
+
+// Summary is a compact cluster-wide health rollup.
+type Summary struct {
+	Counts
+	Namespaces         map[string]*Counts `json:"namespaces"`
+	Kinds              map[string]*Counts `json:"kinds"`
+	UnhealthyWorkloads []Ref              `json:"unhealthy_workloads"`
+}
+
+// Summarize rolls workloads up per namespace and per kind.
+func Summarize(workloads []Workload) Summary {
+	s := Summary{
+		Namespaces:         map[string]*Counts{},
+		Kinds:              map[string]*Counts{},
+		UnhealthyWorkloads: []Ref{},
+	}
+
+	for _, w := range workloads {
+		s.add(w.Health)
+
+		if s.Namespaces[w.Namespace] == nil {
+			s.Namespaces[w.Namespace] = &Counts{}
+		}
+		s.Namespaces[w.Namespace].add(w.Health)
+
+		if s.Kinds[w.Kind] == nil {
+			s.Kinds[w.Kind] = &Counts{}
+		}
+		s.Kinds[w.Kind].add(w.Health)
+
+		if !w.Health {
+			s.UnhealthyWorkloads = append(s.UnhealthyWorkloads, w.Ref)
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,32 @@
+package health
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	workloads := []Workload{
+		{Ref: Ref{Kind: KindDeployment, Namespace: "shop", Name: "checkout"}, Health: true},
+		{Ref: Ref{Kind: KindDeployment, Namespace: "shop", Name: "cart"}, Health: false},
+		{Ref: Ref{Kind: KindStatefulSet, Namespace: "data", Name: "db"}, Health: true},
+	}
+
+	s := Summarize(workloads)
+
+	assert.Equal(t, Counts{Total: 3, Healthy: 2, Unhealthy: 1}, s.Counts)
+	assert.Equal(t, &Counts{Total: 2, Healthy: 1, Unhealthy: 1}, s.Namespaces["shop"])
+	assert.Equal(t, &Counts{Total: 1, Healthy: 1}, s.Namespaces["data"])
+	assert.Equal(t, &Counts{Total: 2, Healthy: 1, Unhealthy: 1}, s.Kinds[KindDeployment])
+	assert.Equal(t, &Counts{Total: 1, Healthy: 1}, s.Kinds[KindStatefulSet])
+	assert.Equal(t, []Ref{{Kind: KindDeployment, Namespace: "shop", Name: "cart"}}, s.UnhealthyWorkloads)
+}
+
+func TestSummarize_Empty(t *testing.T) {
+	s := Summarize(nil)
+
+	assert.Equal(t, 0, s.Total)
+	assert.NotNil(t, s.Namespaces)
+	assert.NotNil(t, s.UnhealthyWorkloads)
+}
@@ -0,0 +1,134 @@
+package health
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Additional check names for non-Deployment workloads.
+const (
+	CheckCurrentRevision    = "current_revision"
+	CheckObservedGeneration = "observed_generation"
+	CheckMisscheduled       = "misscheduled"
+	CheckReplicaFailure     = "replica_failure_condition"
+)
+
+// Workload kinds.
+const (
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "StatefulSet"
+	KindDaemonSet   = "DaemonSet"
+	KindReplicaSet  = "ReplicaSet"
+)
+
+// Ref identifies a workload.
+type Ref struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// Workload is the compact health view of any workload kind.
+type Workload struct {
+	Ref
+	Health        bool     `json:"health"`
+	HealthReasons []Reason `json:"health_reasons"`
+}
+
+// NewWorkload builds the compact view from an evaluation result.
+func NewWorkload(kind, namespace, name string, res Result) Workload {
+	return Workload{
+		Ref:           Ref{Kind: kind, Namespace: namespace, Name: name},
+		Health:        res.Healthy,
+		HealthReasons: res.Reasons,
+	}
+}
+
+// EvaluateStatefulSet checks a StatefulSet against the health rules:
+// 1. ReadyReplicas must match Desired Replicas
+// 2. UpdatedReplicas must match Desired Replicas, less the pods kept back by a partition
+// 3. CurrentRevision must match UpdateRevision (Rolling update finished)
+// 4. ObservedGeneration must reach Generation, else the status is stale
+// OnDelete and partitioned rollouts leave old revisions running on purpose, 2. and 3. are skipped
+// for OnDelete and 3. for a partition above 0.
+func EvaluateStatefulSet(s *appsv1.StatefulSet) Result {
+	res := Result{Reasons: []Reason{}}
+
+	desired := int32(0)
+	if s.Spec.Replicas != nil {
+		desired = *s.Spec.Replicas
+	}
+
+	onDelete := s.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType
+	partition := int32(0)
+	if ru := s.Spec.UpdateStrategy.RollingUpdate; !onDelete && ru != nil && ru.Partition != nil {
+		partition = min(max(*ru.Partition, 0), desired)
+	}
+
+	if s.Status.ReadyReplicas != desired {
+		res.add(CheckReadyReplicas, itoa(s.Status.ReadyReplicas), itoa(desired), "")
+	}
+	if !onDelete && s.Status.UpdatedReplicas != desired-partition {
+		res.add(CheckUpdatedReplicas, itoa(s.Status.UpdatedReplicas), itoa(desired-partition), "")
+	}
+	if !onDelete && partition == 0 && s.Status.CurrentRevision != s.Status.UpdateRevision {
+		res.add(CheckCurrentRevision, s.Status.CurrentRevision, s.Status.UpdateRevision, "")
+	}
+	if s.Status.ObservedGeneration < s.Generation {
+		res.add(CheckObservedGeneration, itoa64(s.Status.ObservedGeneration), itoa64(s.Generation), "status not yet updated for the latest spec")
+	}
+
+	res.Healthy = len(res.Reasons) == 0
+	return res
+}
+
+// EvaluateDaemonSet checks a DaemonSet against the health rules:
+// 1. NumberReady must match DesiredNumberScheduled
+// 2. UpdatedNumberScheduled must match DesiredNumberScheduled
+// 3. NumberUnavailable must be 0
+// 4. NumberMisscheduled must be 0
+func EvaluateDaemonSet(s *appsv1.DaemonSet) Result {
+	res := Result{Reasons: []Reason{}}
+
+	desired := s.Status.DesiredNumberScheduled
+
+	if s.Status.NumberReady != desired {
+		res.add(CheckReadyReplicas, itoa(s.Status.NumberReady), itoa(desired), "")
+	}
+	if s.Status.UpdatedNumberScheduled != desired {
+		res.add(CheckUpdatedReplicas, itoa(s.Status.UpdatedNumberScheduled), itoa(desired), "")
+	}
+	if s.Status.NumberUnavailable != 0 {
+		res.add(CheckUnavailableReplicas, itoa(s.Status.NumberUnavailable), "0", "")
+	}
+	if s.Status.NumberMisscheduled != 0 {
+		res.add(CheckMisscheduled, itoa(s.Status.NumberMisscheduled), "0", "")
+	}
+
+	res.Healthy = len(res.Reasons) == 0
+	return res
+}
+
+// EvaluateReplicaSet checks a ReplicaSet against the health rules:
+// 1. ReadyReplicas must match Desired Replicas
+// 2. Status.conditions type=ReplicaFailure must not be True
+func EvaluateReplicaSet(s *appsv1.ReplicaSet) Result {
+	res := Result{Reasons: []Reason{}}
+
+	desired := int32(0)
+	if s.Spec.Replicas != nil {
+		desired = *s.Spec.Replicas
+	}
+
+	if s.Status.ReadyReplicas != desired {
+		res.add(CheckReadyReplicas, itoa(s.Status.ReadyReplicas), itoa(desired), "")
+	}
+	for _, c := range s.Status.Conditions {
+		if c.Type == appsv1.ReplicaSetReplicaFailure && c.Status == corev1.ConditionTrue {
+			res.add(CheckReplicaFailure, string(c.Status)+"/"+c.Reason, string(corev1.ConditionFalse), c.Message)
+		}
+	}
+
+	res.Healthy = len(res.Reasons) == 0
+	return res
+}
@@ -0,0 +1,121 @@
+package health
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestEvaluateStatefulSet(t *testing.T) {
+	replicas := int32(3)
+	s := &appsv1.StatefulSet{
+		Spec: appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status: appsv1.StatefulSetStatus{
+			ReadyReplicas:   3,
+			UpdatedReplicas: 3,
+			CurrentRevision: "db-7c9f",
+			UpdateRevision:  "db-7c9f",
+		},
+	}
+	assert.True(t, EvaluateStatefulSet(s).Healthy)
+
+	s.Status.UpdateRevision = "db-5b8d"
+	res := EvaluateStatefulSet(s)
+	assert.False(t, res.Healthy)
+	assert.Equal(t, []Reason{
+		{Check: CheckCurrentRevision, Observed: "db-7c9f", Expected: "db-5b8d"},
+	}, res.Reasons)
+}
+
+func TestEvaluateStatefulSet_UpdateStrategy(t *testing.T) {
+	replicas, partition := int32(3), int32(2)
+	// canary: one pod on the new revision, two held back by the partition
+	s := &appsv1.StatefulSet{
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+			},
+		},
+		Status: appsv1.StatefulSetStatus{
+			ReadyReplicas:   3,
+			UpdatedReplicas: 1,
+			CurrentRevision: "db-7c9f",
+			UpdateRevision:  "db-5b8d",
+		},
+	}
+	assert.True(t, EvaluateStatefulSet(s).Healthy)
+
+	s.Status.UpdatedReplicas = 0
+	assert.Equal(t, []Reason{
+		{Check: CheckUpdatedReplicas, Observed: "0", Expected: "1"},
+	}, EvaluateStatefulSet(s).Reasons)
+
+	s.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+	assert.True(t, EvaluateStatefulSet(s).Healthy, "OnDelete waits for pods to be deleted by hand")
+}
+
+func TestEvaluateStatefulSet_ObservedGeneration(t *testing.T) {
+	replicas := int32(1)
+	s := &appsv1.StatefulSet{
+		Spec: appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status: appsv1.StatefulSetStatus{
+			ReadyReplicas:      1,
+			UpdatedReplicas:    1,
+			ObservedGeneration: 3,
+		},
+	}
+	s.Generation = 4
+
+	res := EvaluateStatefulSet(s)
+	assert.False(t, res.Healthy)
+	assert.Equal(t, []Reason{
+		{Check: CheckObservedGeneration, Observed: "3", Expected: "4", Message: "status not yet updated for the latest spec"},
+	}, res.Reasons)
+
+	s.Status.ObservedGeneration = 4
+	assert.True(t, EvaluateStatefulSet(s).Healthy)
+}
+
+func TestEvaluateDaemonSet(t *testing.T) {
+	s := &appsv1.DaemonSet{
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 4,
+			NumberReady:            4,
+			UpdatedNumberScheduled: 4,
+		},
+	}
+	assert.True(t, EvaluateDaemonSet(s).Healthy)
+
+	s.Status.NumberReady = 3
+	s.Status.NumberUnavailable = 1
+	s.Status.NumberMisscheduled = 2
+	res := EvaluateDaemonSet(s)
+	assert.False(t, res.Healthy)
+	assert.Equal(t, []Reason{
+		{Check: CheckReadyReplicas, Observed: "3", Expected: "4"},
+		{Check: CheckUnavailableReplicas, Observed: "1", Expected: "0"},
+		{Check: CheckMisscheduled, Observed: "2", Expected: "0"},
+	}, res.Reasons)
+}
+
+func TestEvaluateReplicaSet(t *testing.T) {
+	replicas := int32(2)
+	s := &appsv1.ReplicaSet{
+		Spec:   appsv1.ReplicaSetSpec{Replicas: &replicas},
+		Status: appsv1.ReplicaSetStatus{ReadyReplicas: 2},
+	}
+	assert.True(t, EvaluateReplicaSet(s).Healthy)
+
+	s.Status.Conditions = []appsv1.ReplicaSetCondition{
+		{Type: appsv1.ReplicaSetReplicaFailure, Status: corev1.ConditionTrue, Reason: "FailedCreate", Message: "quota exceeded"},
+	}
+	res := EvaluateReplicaSet(s)
+	assert.False(t, res.Healthy)
+	assert.Equal(t, []Reason{
+		{Check: CheckReplicaFailure, Observed: "True/FailedCreate", Expected: "False", Message: "quota exceeded"},
+	}, res.Reasons)
+}
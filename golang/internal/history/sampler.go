@@ -0,0 +1,109 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Sampler polls the deployments of one cluster and records their health transitions.
+type Sampler struct {
+	Store   *Store
+	Cluster string
+	Client  *k8s.Client
+	// Time between samples
+	Interval time.Duration
+	// Transitions older than Retention are pruned, zero keeps everything
+	Retention time.Duration
+
+	// last recorded state by deployment, seeded from the store
+	last map[Key]Transition
+}
+
+// Run samples until ctx is done, pruning expired transitions once per hour.
+func (s *Sampler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	lastPrune := time.Time{}
+	for {
+		now := time.Now()
+		if err := s.Sample(ctx, now); err != nil {
+			fmt.Println("failed to sample deployment health", s.Cluster, err)
+		}
+
+		if s.Retention > 0 && now.Sub(lastPrune) >= time.Hour {
+			if _, err := s.Store.Prune(now.Add(-s.Retention)); err != nil {
+				fmt.Println("failed to prune health history", err)
+			}
+			lastPrune = now
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sample evaluates every deployment like /deployments does and records those whose health changed,
+// first seen, or deleted since the previous sample.
+func (s *Sampler) Sample(ctx context.Context, now time.Time) error {
+	if s.last == nil {
+		last, err := s.Store.Latest(s.Cluster)
+		if err != nil {
+			return err
+		}
+		s.last = last
+	}
+
+	deployments, err := s.Client.ListDeployments(ctx, "", metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	hpas, err := s.Client.ListHorizontalPodAutoscalers(ctx, "", metav1.ListOptions{})
+	if err != nil {
+		// health does not depend on the autoscaler, keep sampling without it
+		fmt.Println("failed to list hpas for health history", s.Cluster, err)
+	}
+
+	seen := map[Key]bool{}
+	for _, d := range deployments {
+		key := Key{Cluster: s.Cluster, Namespace: d.Namespace, Name: d.Name}
+		seen[key] = true
+
+		result, _ := health.EvaluateDeploymentAutoscaled(&d, hpas)
+
+		last, known := s.last[key]
+		if known && !last.Deleted && last.Healthy == result.Healthy {
+			continue
+		}
+		if err := s.record(key, Transition{Time: now, Healthy: result.Healthy, Reasons: result.Reasons}); err != nil {
+			return err
+		}
+	}
+
+	for key, last := range s.last {
+		if seen[key] || last.Deleted {
+			continue
+		}
+		if err := s.record(key, Transition{Time: now, Deleted: true}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Sampler) record(key Key, t Transition) error {
+	if err := s.Store.Record(key, t); err != nil {
+		return err
+	}
+	s.last[key] = t
+	return nil
+}
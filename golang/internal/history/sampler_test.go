@@ -0,0 +1,101 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	testing2 "k8s.io/client-go/testing"
+)
+
+func deployment(name string, ready int32) *appsv1.Deployment {
+	replicas := int32(1)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: ready, AvailableReplicas: ready,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+func TestSampler_Sample(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := Open(dir)
+	require.NoError(t, err)
+
+	clientset := fake.NewSimpleClientset(deployment("web", 1), deployment("api", 0))
+	sampler := &Sampler{Store: store, Cluster: "prod", Client: &k8s.Client{Clientset: clientset}}
+	web := Key{Cluster: "prod", Namespace: "default", Name: "web"}
+	api := Key{Cluster: "prod", Namespace: "default", Name: "api"}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, sampler.Sample(ctx, base))
+	// unchanged, nothing recorded
+	require.NoError(t, sampler.Sample(ctx, base.Add(time.Minute)))
+
+	_, err = clientset.AppsV1().Deployments("default").Update(ctx, deployment("web", 0), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, clientset.AppsV1().Deployments("default").Delete(ctx, "api", metav1.DeleteOptions{}))
+	require.NoError(t, sampler.Sample(ctx, base.Add(2*time.Minute)))
+
+	transitions, err := store.Range(web, base, base.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, transitions, 2)
+	assert.True(t, transitions[0].Healthy)
+	assert.False(t, transitions[1].Healthy)
+	assert.NotEmpty(t, transitions[1].Reasons)
+
+	transitions, err = store.Range(api, base, base.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, transitions, 2)
+	assert.False(t, transitions[0].Healthy)
+	assert.True(t, transitions[1].Deleted)
+
+	// a restarted sampler resumes from the store
+	require.NoError(t, store.Close())
+	store, err = Open(dir)
+	require.NoError(t, err)
+	defer store.Close()
+	sampler = &Sampler{Store: store, Cluster: "prod", Client: &k8s.Client{Clientset: clientset}}
+	require.NoError(t, sampler.Sample(ctx, base.Add(3*time.Minute)))
+
+	transitions, err = store.Range(web, base, base.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, transitions, 2)
+}
+
+func TestSampler_SampleWithoutHPAs(t *testing.T) {
+	store, err := Open(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	clientset := fake.NewSimpleClientset(deployment("web", 0))
+	clientset.PrependReactor("list", "horizontalpodautoscalers", func(action testing2.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewNotFound(schema.GroupResource{Group: "autoscaling", Resource: "horizontalpodautoscalers"}, "")
+	})
+	sampler := &Sampler{Store: store, Cluster: "prod", Client: &k8s.Client{Clientset: clientset}}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, sampler.Sample(context.Background(), base))
+
+	transitions, err := store.Range(Key{Cluster: "prod", Namespace: "default", Name: "web"}, base, base.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.False(t, transitions[0].Healthy)
+}
@@ -0,0 +1,232 @@
+// Package history records deployment health transitions in an embedded bbolt store.
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	bolt "go.etcd.io/bbolt"
+)
+
+// FileName is the store file in the data directory.
+const FileName = "history.db"
+
+// Root bucket, holding one nested bucket per deployment keyed by Key.String().
+// Transitions in a deployment bucket are keyed by big endian unix nanoseconds.
+var deploymentsBucket = []byte("deployments")
+
+// Key identifies a deployment across clusters.
+type Key struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+func (k Key) String() string {
+	return k.Cluster + "/" + k.Namespace + "/" + k.Name
+}
+
+func parseKey(s string) (Key, bool) {
+	parts := strings.SplitN(s, "/", 3)
+	if len(parts) != 3 {
+		return Key{}, false
+	}
+	return Key{Cluster: parts[0], Namespace: parts[1], Name: parts[2]}, true
+}
+
+// Transition is a change of deployment health, in effect until the next one.
+type Transition struct {
+	Time    time.Time       `json:"time"`
+	Healthy bool            `json:"healthy"`
+	Deleted bool            `json:"deleted,omitempty"`
+	Reasons []health.Reason `json:"reasons,omitempty"`
+}
+
+// Store persists transitions on disk.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open creates or opens the store in dir.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(filepath.Join(dir, FileName), 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(deploymentsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Record appends a transition.
+func (s *Store) Record(key Key, t Transition) error {
+	value, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(deploymentsBucket).CreateBucketIfNotExists([]byte(key.String()))
+		if err != nil {
+			return err
+		}
+		return b.Put(timeKey(t.Time), value)
+	})
+}
+
+// Latest returns the last transition of every deployment of a cluster.
+func (s *Store) Latest(cluster string) (map[Key]Transition, error) {
+	latest := map[Key]Transition{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(deploymentsBucket).ForEachBucket(func(name []byte) error {
+			key, ok := parseKey(string(name))
+			if !ok || key.Cluster != cluster {
+				return nil
+			}
+			_, v := tx.Bucket(deploymentsBucket).Bucket(name).Cursor().Last()
+			if v == nil {
+				return nil
+			}
+			var t Transition
+			if err := json.Unmarshal(v, &t); err != nil {
+				return err
+			}
+			latest[key] = t
+			return nil
+		})
+	})
+	return latest, err
+}
+
+// Keys lists the deployments with recorded history in a cluster.
+func (s *Store) Keys(cluster string) ([]Key, error) {
+	keys := []Key{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(deploymentsBucket).ForEachBucket(func(name []byte) error {
+			if key, ok := parseKey(string(name)); ok && key.Cluster == cluster {
+				keys = append(keys, key)
+			}
+			return nil
+		})
+	})
+	return keys, err
+}
+
+// Range returns the transitions between start and end, preceded by the one in effect at start if any.
+func (s *Store) Range(key Key, start, end time.Time) ([]Transition, error) {
+	transitions := []Transition{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(deploymentsBucket).Bucket([]byte(key.String()))
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		k, v := c.Seek(timeKey(start))
+		// the state at start was set by the previous transition
+		switch {
+		case k == nil:
+			if lk, lv := c.Last(); lk != nil {
+				k, v = lk, lv
+			}
+		case !bytes.Equal(k, timeKey(start)):
+			if pk, pv := c.Prev(); pk != nil {
+				k, v = pk, pv
+			} else {
+				k, v = c.First()
+			}
+		}
+
+		endKey := timeKey(end)
+		for ; k != nil && bytes.Compare(k, endKey) <= 0; k, v = c.Next() {
+			var t Transition
+			if err := json.Unmarshal(v, &t); err != nil {
+				return err
+			}
+			transitions = append(transitions, t)
+		}
+		return nil
+	})
+	return transitions, err
+}
+
+// Prune deletes transitions older than before. The last one before the cutoff is kept,
+// it holds the state at the start of the retention window, unless it records a deletion.
+func (s *Store) Prune(before time.Time) (int, error) {
+	pruned := 0
+	cutoff := timeKey(before)
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket(deploymentsBucket)
+
+		var gone [][]byte
+		err := root.ForEachBucket(func(name []byte) error {
+			b := root.Bucket(name)
+
+			var old [][]byte
+			var last []byte
+			c := b.Cursor()
+			for k, v := c.First(); k != nil && bytes.Compare(k, cutoff) < 0; k, v = c.Next() {
+				old = append(old, bytes.Clone(k))
+				last = v
+			}
+			if len(old) == 0 {
+				return nil
+			}
+
+			// nothing newer than a deletion, forget the deployment
+			if k, _ := c.Seek(cutoff); k == nil {
+				var t Transition
+				if err := json.Unmarshal(last, &t); err == nil && t.Deleted {
+					pruned += len(old)
+					gone = append(gone, bytes.Clone(name))
+					return nil
+				}
+			}
+
+			for _, k := range old[:len(old)-1] {
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+				pruned++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, name := range gone {
+			if err := root.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return pruned, err
+}
+
+func timeKey(t time.Time) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(t.UnixNano()))
+	return k
+}
@@ -0,0 +1,79 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Range(t *testing.T) {
+	store, err := Open(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	key := Key{Cluster: "prod", Namespace: "default", Name: "web"}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, healthy := range []bool{true, false, true} {
+		require.NoError(t, store.Record(key, Transition{Time: base.Add(time.Duration(i) * time.Hour), Healthy: healthy}))
+	}
+	// other cluster, same deployment
+	require.NoError(t, store.Record(Key{Cluster: "dev", Namespace: "default", Name: "web"}, Transition{Time: base, Healthy: false}))
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       []bool
+	}{
+		{"State at start precedes the range", base.Add(30 * time.Minute), base.Add(90 * time.Minute), []bool{true, false}},
+		{"Start on a transition", base.Add(time.Hour), base.Add(3 * time.Hour), []bool{false, true}},
+		{"After the last transition", base.Add(5 * time.Hour), base.Add(6 * time.Hour), []bool{true}},
+		{"Before the first transition", base.Add(-2 * time.Hour), base.Add(-time.Hour), []bool{}},
+		{"Everything", base.Add(-time.Hour), base.Add(time.Hour * 3), []bool{true, false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transitions, err := store.Range(key, tt.start, tt.end)
+			require.NoError(t, err)
+			got := []bool{}
+			for _, tr := range transitions {
+				got = append(got, tr.Healthy)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	transitions, err := store.Range(Key{Cluster: "prod", Namespace: "default", Name: "missing"}, base, base.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, transitions)
+}
+
+func TestStore_Prune(t *testing.T) {
+	store, err := Open(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	web := Key{Cluster: "prod", Namespace: "default", Name: "web"}
+	gone := Key{Cluster: "prod", Namespace: "default", Name: "gone"}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, store.Record(web, Transition{Time: base.Add(time.Duration(i) * time.Hour), Healthy: i%2 == 0}))
+	}
+	require.NoError(t, store.Record(gone, Transition{Time: base, Healthy: true}))
+	require.NoError(t, store.Record(gone, Transition{Time: base.Add(time.Hour), Deleted: true}))
+
+	pruned, err := store.Prune(base.Add(90 * time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 3, pruned)
+
+	// the state at the cutoff survives
+	transitions, err := store.Range(web, base, base.Add(3*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, transitions, 2)
+	assert.False(t, transitions[0].Healthy)
+
+	keys, err := store.Keys("prod")
+	require.NoError(t, err)
+	assert.Equal(t, []Key{web}, keys)
+}
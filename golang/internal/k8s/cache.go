@@ -0,0 +1,272 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Cached resources.
+const (
+	ResourceDeployments     = "deployments"
+	ResourceStatefulSets    = "statefulsets"
+	ResourceDaemonSets      = "daemonsets"
+	ResourceReplicaSets     = "replicasets"
+	ResourcePods            = "pods"
+	ResourceNetworkPolicies = "networkpolicies"
+	ResourceHPAs            = "horizontalpodautoscalers"
+)
+
+// Informer indexers, in addition to cache.NamespaceIndex.
+const (
+	IndexUID   = "uid"
+	IndexLabel = "label" // "key=value"
+)
+
+// Cache serves reads from shared informers instead of live List calls.
+type Cache struct {
+	factory   informers.SharedInformerFactory
+	informers map[string]cache.SharedIndexInformer
+}
+
+// CacheStatus reports whether each informer completed its initial sync.
+type CacheStatus struct {
+	Synced    bool            `json:"synced"`
+	Resources map[string]bool `json:"resources"`
+}
+
+type consistentReadKey struct{}
+
+// WithConsistentRead marks ctx so reads bypass the cache and hit the API server.
+func WithConsistentRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, consistentReadKey{}, true)
+}
+
+func isConsistentRead(ctx context.Context) bool {
+	v, _ := ctx.Value(consistentReadKey{}).(bool)
+	return v
+}
+
+// NewCache registers informers for every cached resource.
+// Informers are not running until Start is called.
+func NewCache(clientset kubernetes.Interface, resync time.Duration) *Cache {
+	factory := informers.NewSharedInformerFactory(clientset, resync)
+	c := &Cache{
+		factory: factory,
+		informers: map[string]cache.SharedIndexInformer{
+			ResourceDeployments:     factory.Apps().V1().Deployments().Informer(),
+			ResourceStatefulSets:    factory.Apps().V1().StatefulSets().Informer(),
+			ResourceDaemonSets:      factory.Apps().V1().DaemonSets().Informer(),
+			ResourceReplicaSets:     factory.Apps().V1().ReplicaSets().Informer(),
+			ResourcePods:            factory.Core().V1().Pods().Informer(),
+			ResourceNetworkPolicies: factory.Networking().V1().NetworkPolicies().Informer(),
+			ResourceHPAs:            factory.Autoscaling().V2().HorizontalPodAutoscalers().Informer(),
+		},
+	}
+
+	for _, inf := range c.informers {
+		// Only fails if the informer has already started
+		_ = inf.AddIndexers(cache.Indexers{
+			IndexUID:   uidIndexFunc,
+			IndexLabel: labelIndexFunc,
+		})
+	}
+
+	return c
+}
+
+// Start runs the informers until ctx is done. It does not wait for sync.
+func (c *Cache) Start(ctx context.Context) {
+	c.factory.Start(ctx.Done())
+}
+
+// WaitForSync blocks until all informers synced or ctx is done.
+func (c *Cache) WaitForSync(ctx context.Context) bool {
+	synced := true
+	for _, ok := range c.factory.WaitForCacheSync(ctx.Done()) {
+		synced = synced && ok
+	}
+	return synced
+}
+
+// Status reports per-resource sync state.
+func (c *Cache) Status() CacheStatus {
+	status := CacheStatus{Synced: true, Resources: map[string]bool{}}
+	for name, inf := range c.informers {
+		status.Resources[name] = inf.HasSynced()
+		status.Synced = status.Synced && status.Resources[name]
+	}
+	return status
+}
+
+// indexer returns the informer store for resource, or nil when the cache cannot serve it.
+func (c *Cache) indexer(ctx context.Context, resource string) cache.Indexer {
+	if c == nil || isConsistentRead(ctx) {
+		return nil
+	}
+	inf, ok := c.informers[resource]
+	if !ok || !inf.HasSynced() {
+		return nil
+	}
+	return inf.GetIndexer()
+}
+
+// Informer returns the shared informer of resource, or nil if not cached.
+func (c *Cache) Informer(resource string) cache.SharedIndexInformer {
+	if c == nil {
+		return nil
+	}
+	return c.informers[resource]
+}
+
+// deepCopier is satisfied by pointers to Kubernetes API types.
+type deepCopier[T any] interface {
+	*T
+	DeepCopy() *T
+}
+
+// listCached serves a List from the cache.
+// ok is false when the caller must fall back to a live List,
+// e.g. pagination, an unsupported field selector or an unsynced informer.
+func listCached[T any, PT deepCopier[T]](ctx context.Context, c *Cache, resource, namespace string, opts metav1.ListOptions) (items []T, ok bool) {
+	indexer := c.indexer(ctx, resource)
+	if indexer == nil || opts.Limit > 0 || opts.Continue != "" || opts.ResourceVersion != "" {
+		return nil, false
+	}
+
+	labelSel, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		// let the API server report the error
+		return nil, false
+	}
+	fieldSel, err := fields.ParseSelector(opts.FieldSelector)
+	if err != nil {
+		return nil, false
+	}
+	for _, req := range fieldSel.Requirements() {
+		if req.Field != "metadata.name" && req.Field != "metadata.namespace" {
+			return nil, false
+		}
+	}
+
+	var objs []interface{}
+	switch {
+	case namespace != "":
+		objs, err = indexer.ByIndex(cache.NamespaceIndex, namespace)
+	case equalityTerm(labelSel) != "":
+		objs, err = indexer.ByIndex(IndexLabel, equalityTerm(labelSel))
+	default:
+		objs = indexer.List()
+	}
+	if err != nil {
+		return nil, false
+	}
+
+	items = make([]T, 0, len(objs))
+	for _, obj := range objs {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		if !labelSel.Matches(labels.Set(o.GetLabels())) {
+			continue
+		}
+		if !fieldSel.Matches(fields.Set{"metadata.name": o.GetName(), "metadata.namespace": o.GetNamespace()}) {
+			continue
+		}
+		items = append(items, *PT(obj.(*T)).DeepCopy())
+	}
+
+	// keep the API server ordering (namespace, name)
+	sort.Slice(items, func(i, j int) bool {
+		a, _ := meta.Accessor(PT(&items[i]))
+		b, _ := meta.Accessor(PT(&items[j]))
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		return a.GetName() < b.GetName()
+	})
+
+	return items, true
+}
+
+// getCached serves a Get by namespace and name from the cache.
+func getCached[T any, PT deepCopier[T]](ctx context.Context, c *Cache, resource, namespace, name string) (item *T, ok bool, err error) {
+	indexer := c.indexer(ctx, resource)
+	if indexer == nil {
+		return nil, false, nil
+	}
+
+	obj, exists, err := indexer.GetByKey(namespace + "/" + name)
+	if err != nil {
+		return nil, false, nil
+	}
+	if !exists {
+		return nil, true, apierrors.NewNotFound(schema.GroupResource{Resource: resource}, name)
+	}
+	return PT(obj.(*T)).DeepCopy(), true, nil
+}
+
+// getCachedByUID serves a lookup by UID from the cache.
+func getCachedByUID[T any, PT deepCopier[T]](ctx context.Context, c *Cache, resource, uid string) (item *T, ok bool) {
+	indexer := c.indexer(ctx, resource)
+	if indexer == nil {
+		return nil, false
+	}
+
+	objs, err := indexer.ByIndex(IndexUID, uid)
+	if err != nil {
+		return nil, false
+	}
+	if len(objs) == 0 {
+		return nil, true
+	}
+	return PT(objs[0].(*T)).DeepCopy(), true
+}
+
+// equalityTerm returns the first "key=value" requirement usable with IndexLabel.
+func equalityTerm(sel labels.Selector) string {
+	reqs, _ := sel.Requirements()
+	for _, req := range reqs {
+		values := req.Values().List()
+		if len(values) != 1 {
+			continue
+		}
+		switch req.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			return req.Key() + "=" + values[0]
+		}
+	}
+	return ""
+}
+
+func uidIndexFunc(obj interface{}) ([]string, error) {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	return []string{string(o.GetUID())}, nil
+}
+
+func labelIndexFunc(obj interface{}) ([]string, error) {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(o.GetLabels()))
+	for k, v := range o.GetLabels() {
+		keys = append(keys, k+"="+v)
+	}
+	return keys, nil
+}
@@ -0,0 +1,113 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// countLists returns how many live list calls were made for resource.
+func countLists(clientset *fake.Clientset, resource string) int {
+	n := 0
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == "list" && action.GetResource().Resource == resource {
+			n++
+		}
+	}
+	return n
+}
+
+func TestCache_ListDeployments(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "ns-a", Labels: map[string]string{"app": "b"}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "ns-a", Labels: map[string]string{"app": "a"}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "ns-b", Labels: map[string]string{"app": "a"}}},
+	)
+	client := &Client{Clientset: clientset}
+	assert.True(t, client.StartCache(ctx, time.Minute).WaitForSync(ctx))
+
+	// informer initial list
+	lists := countLists(clientset, "deployments")
+
+	all, err := client.ListDeployments(ctx, "", metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, all, 3)
+	assert.Equal(t, "a", all[0].Name)
+	assert.Equal(t, "b", all[1].Name)
+
+	byNs, err := client.ListDeployments(ctx, "ns-a", metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, byNs, 2)
+
+	byLabel, err := client.ListDeployments(ctx, "", metav1.ListOptions{LabelSelector: "app=a"})
+	assert.NoError(t, err)
+	assert.Len(t, byLabel, 2)
+
+	byName, err := client.ListDeployments(ctx, "", metav1.ListOptions{FieldSelector: "metadata.name=c"})
+	assert.NoError(t, err)
+	assert.Len(t, byName, 1)
+
+	d, err := client.GetDeployment(ctx, "ns-b", "c")
+	assert.NoError(t, err)
+	assert.Equal(t, "c", d.Name)
+
+	_, err = client.GetDeployment(ctx, "ns-b", "missing")
+	assert.Error(t, err)
+
+	// all served from cache
+	assert.Equal(t, lists, countLists(clientset, "deployments"))
+
+	// consistent read and pagination go live
+	_, err = client.ListDeployments(WithConsistentRead(ctx), "", metav1.ListOptions{})
+	assert.NoError(t, err)
+	_, err = client.ListDeployments(ctx, "", metav1.ListOptions{Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, lists+2, countLists(clientset, "deployments"))
+}
+
+func TestCache_GetNetworkPolicyByUID(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientset := fake.NewSimpleClientset(
+		&networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: "default", UID: "12345"}},
+	)
+	client := &Client{Clientset: clientset}
+	assert.True(t, client.StartCache(ctx, time.Minute).WaitForSync(ctx))
+	lists := countLists(clientset, "networkpolicies")
+
+	policy, err := client.GetNetworkPolicyByUID(ctx, "", "12345")
+	assert.NoError(t, err)
+	assert.Equal(t, "p", policy.Name)
+
+	_, err = client.GetNetworkPolicyByUID(ctx, "other", "12345")
+	assert.Error(t, err)
+
+	_, err = client.GetNetworkPolicyByUID(ctx, "", "99999")
+	assert.Error(t, err)
+
+	assert.Equal(t, lists, countLists(clientset, "networkpolicies"))
+}
+
+func TestCheckConnectivity_CacheStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &Client{Clientset: fake.NewSimpleClientset()}
+	assert.Nil(t, client.CheckConnectivity(ctx).Cache)
+
+	assert.True(t, client.StartCache(ctx, time.Minute).WaitForSync(ctx))
+	status := client.CheckConnectivity(ctx)
+	assert.NotNil(t, status.Cache)
+	assert.True(t, status.Cache.Synced)
+	assert.True(t, status.Cache.Resources[ResourceDeployments])
+}
@@ -3,17 +3,34 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
 // Client wraps the Kubernetes clientset.
+// Reads are served from the informer cache once it is started and synced.
 type Client struct {
 	Clientset kubernetes.Interface
+
+	cache *Cache
+	// cluster name, set by Registry.Add
+	name string
+}
+
+// Name is the cluster name the client is registered under, empty outside a Registry.
+func (c *Client) Name() string {
+	return c.name
 }
 
 // NewClient creates a new Kubernetes client based on the provided kubeconfig path
@@ -34,6 +51,18 @@ func NewClient(kubeconfig string) (*Client, error) {
 	}, nil
 }
 
+// StartCache starts the informer cache. Reads fall back to live calls until it has synced.
+func (c *Client) StartCache(ctx context.Context, resync time.Duration) *Cache {
+	c.cache = NewCache(c.Clientset, resync)
+	c.cache.Start(ctx)
+	return c.cache
+}
+
+// Cache returns the informer cache, nil if not started.
+func (c *Client) Cache() *Cache {
+	return c.cache
+}
+
 func GetKubernetesVersion(clientset kubernetes.Interface) (string, error) {
 	version, err := clientset.Discovery().ServerVersion()
 	if err != nil {
@@ -49,6 +78,9 @@ type ReachabilityStatus struct {
 	Discovery    bool   `json:"discovery"`
 	Error        string `json:"error,omitempty"`
 	Version      string `json:"version,omitempty"`
+
+	// informer cache sync state, omitted when the cache is disabled
+	Cache *CacheStatus `json:"cache,omitempty"`
 }
 
 // Check Connectivity between the Kubernetes API server
@@ -78,11 +110,19 @@ func (c *Client) CheckConnectivity(ctx context.Context) ReachabilityStatus {
 
 	status.Status = status.Reachability && status.Discovery
 
+	if c.cache != nil {
+		cacheStatus := c.cache.Status()
+		status.Cache = &cacheStatus
+	}
+
 	return status
 }
 
 // Get List Deployments leave empty to get all
 func (c *Client) ListDeployments(ctx context.Context, namespace string, opts metav1.ListOptions) ([]appsv1.Deployment, error) {
+	if items, ok := listCached[appsv1.Deployment](ctx, c.cache, ResourceDeployments, namespace, opts); ok {
+		return items, nil
+	}
 	deps, err := c.Clientset.AppsV1().Deployments(namespace).List(ctx, opts)
 	if err != nil {
 		return nil, err
@@ -90,8 +130,137 @@ func (c *Client) ListDeployments(ctx context.Context, namespace string, opts met
 	return deps.Items, nil
 }
 
+// ListDeploymentPage always reads live and keeps the list metadata
+// (resourceVersion, continue token, remaining count).
+func (c *Client) ListDeploymentPage(ctx context.Context, namespace string, opts metav1.ListOptions) (*appsv1.DeploymentList, error) {
+	return c.Clientset.AppsV1().Deployments(namespace).List(ctx, opts)
+}
+
+// WatchDeployments starts a watch, use opts.ResourceVersion to resume.
+func (c *Client) WatchDeployments(ctx context.Context, namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return c.Clientset.AppsV1().Deployments(namespace).Watch(ctx, opts)
+}
+
+// Get a single Deployment by namespace and name.
+func (c *Client) GetDeployment(ctx context.Context, namespace, name string) (*appsv1.Deployment, error) {
+	if d, ok, err := getCached[appsv1.Deployment](ctx, c.cache, ResourceDeployments, namespace, name); ok {
+		return d, err
+	}
+	return c.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// Patch a Deployment, e.g. restart, rollback or pause.
+func (c *Client) PatchDeployment(ctx context.Context, namespace, name string, pt types.PatchType, data []byte) (*appsv1.Deployment, error) {
+	return c.Clientset.AppsV1().Deployments(namespace).Patch(ctx, name, pt, data, metav1.PatchOptions{})
+}
+
+// Get the scale subresource of a Deployment.
+func (c *Client) GetDeploymentScale(ctx context.Context, namespace, name string) (*autoscalingv1.Scale, error) {
+	return c.Clientset.AppsV1().Deployments(namespace).GetScale(ctx, name, metav1.GetOptions{})
+}
+
+// Update the scale subresource of a Deployment.
+func (c *Client) UpdateDeploymentScale(ctx context.Context, namespace, name string, scale *autoscalingv1.Scale) (*autoscalingv1.Scale, error) {
+	return c.Clientset.AppsV1().Deployments(namespace).UpdateScale(ctx, name, scale, metav1.UpdateOptions{})
+}
+
+// Get List HorizontalPodAutoscalers leave empty to get all
+func (c *Client) ListHorizontalPodAutoscalers(ctx context.Context, namespace string, opts metav1.ListOptions) ([]autoscalingv2.HorizontalPodAutoscaler, error) {
+	if items, ok := listCached[autoscalingv2.HorizontalPodAutoscaler](ctx, c.cache, ResourceHPAs, namespace, opts); ok {
+		return items, nil
+	}
+	hpas, err := c.Clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return hpas.Items, nil
+}
+
+// Get List StatefulSets leave empty to get all
+func (c *Client) ListStatefulSets(ctx context.Context, namespace string, opts metav1.ListOptions) ([]appsv1.StatefulSet, error) {
+	if items, ok := listCached[appsv1.StatefulSet](ctx, c.cache, ResourceStatefulSets, namespace, opts); ok {
+		return items, nil
+	}
+	sets, err := c.Clientset.AppsV1().StatefulSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return sets.Items, nil
+}
+
+// Get List DaemonSets leave empty to get all
+func (c *Client) ListDaemonSets(ctx context.Context, namespace string, opts metav1.ListOptions) ([]appsv1.DaemonSet, error) {
+	if items, ok := listCached[appsv1.DaemonSet](ctx, c.cache, ResourceDaemonSets, namespace, opts); ok {
+		return items, nil
+	}
+	sets, err := c.Clientset.AppsV1().DaemonSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return sets.Items, nil
+}
+
+// Get List ReplicaSets leave empty to get all
+func (c *Client) ListReplicaSets(ctx context.Context, namespace string, opts metav1.ListOptions) ([]appsv1.ReplicaSet, error) {
+	if items, ok := listCached[appsv1.ReplicaSet](ctx, c.cache, ResourceReplicaSets, namespace, opts); ok {
+		return items, nil
+	}
+	sets, err := c.Clientset.AppsV1().ReplicaSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return sets.Items, nil
+}
+
+// Get List Pods leave empty to get all
+func (c *Client) ListPods(ctx context.Context, namespace string, opts metav1.ListOptions) ([]corev1.Pod, error) {
+	if items, ok := listCached[corev1.Pod](ctx, c.cache, ResourcePods, namespace, opts); ok {
+		return items, nil
+	}
+	pods, err := c.Clientset.CoreV1().Pods(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// Get List Namespaces
+func (c *Client) ListNamespaces(ctx context.Context, opts metav1.ListOptions) ([]corev1.Namespace, error) {
+	namespaces, err := c.Clientset.CoreV1().Namespaces().List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return namespaces.Items, nil
+}
+
+// Get List PodDisruptionBudgets leave empty to get all
+func (c *Client) ListPodDisruptionBudgets(ctx context.Context, namespace string, opts metav1.ListOptions) ([]policyv1.PodDisruptionBudget, error) {
+	pdbs, err := c.Clientset.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return pdbs.Items, nil
+}
+
+// ListEvents always reads live, events are too noisy to cache.
+func (c *Client) ListEvents(ctx context.Context, namespace string, opts metav1.ListOptions) ([]corev1.Event, error) {
+	events, err := c.Clientset.CoreV1().Events(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return events.Items, nil
+}
+
+// CreateEvent records an event, e.g. an action taken without a user request.
+func (c *Client) CreateEvent(ctx context.Context, event *corev1.Event) (*corev1.Event, error) {
+	return c.Clientset.CoreV1().Events(event.Namespace).Create(ctx, event, metav1.CreateOptions{})
+}
+
 // if ns is empty, it returns all across all namespaces.
 func (c *Client) ListNetworkPolicies(ctx context.Context, namespace string, opts metav1.ListOptions) ([]networkingv1.NetworkPolicy, error) {
+	if items, ok := listCached[networkingv1.NetworkPolicy](ctx, c.cache, ResourceNetworkPolicies, namespace, opts); ok {
+		return items, nil
+	}
 	pols, err := c.Clientset.NetworkingV1().NetworkPolicies(namespace).List(ctx, opts)
 	if err != nil {
 		return nil, err
@@ -99,10 +268,21 @@ func (c *Client) ListNetworkPolicies(ctx context.Context, namespace string, opts
 	return pols.Items, nil
 }
 
+// ListNetworkPolicyPage always reads live and keeps the list metadata.
+func (c *Client) ListNetworkPolicyPage(ctx context.Context, namespace string, opts metav1.ListOptions) (*networkingv1.NetworkPolicyList, error) {
+	return c.Clientset.NetworkingV1().NetworkPolicies(namespace).List(ctx, opts)
+}
+
 func (c *Client) CreateNetworkPolicy(ctx context.Context, policy *networkingv1.NetworkPolicy) (*networkingv1.NetworkPolicy, error) {
 	return c.Clientset.NetworkingV1().NetworkPolicies(policy.Namespace).Create(ctx, policy, metav1.CreateOptions{})
 }
 
+// DryRunCreateNetworkPolicy validates the policy on the API server without persisting it,
+// returning the object as it would be stored.
+func (c *Client) DryRunCreateNetworkPolicy(ctx context.Context, policy *networkingv1.NetworkPolicy) (*networkingv1.NetworkPolicy, error) {
+	return c.Clientset.NetworkingV1().NetworkPolicies(policy.Namespace).Create(ctx, policy, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+}
+
 // delete by name and namespace.
 func (c *Client) DeleteNetworkPolicy(ctx context.Context, namespace, name string) error {
 	return c.Clientset.NetworkingV1().NetworkPolicies(namespace).Delete(ctx, name, metav1.DeleteOptions{})
@@ -111,6 +291,13 @@ func (c *Client) DeleteNetworkPolicy(ctx context.Context, namespace, name string
 // Search by UID.
 // It searches in the specified namespace, or all namespaces if "namespace" is empty.
 func (c *Client) GetNetworkPolicyByUID(ctx context.Context, namespace, uid string) (*networkingv1.NetworkPolicy, error) {
+	if policy, ok := getCachedByUID[networkingv1.NetworkPolicy](ctx, c.cache, ResourceNetworkPolicies, uid); ok {
+		if policy == nil || (namespace != "" && policy.Namespace != namespace) {
+			return nil, fmt.Errorf("network policy with UID %s not found", uid)
+		}
+		return policy, nil
+	}
+
 	list, err := c.ListNetworkPolicies(ctx, namespace, metav1.ListOptions{})
 	if err != nil {
 		return nil, err
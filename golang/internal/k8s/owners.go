@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ListOwnedReplicaSets returns the ReplicaSets controlled by the Deployment.
+// Candidates are narrowed by the Deployment selector, then matched by owner UID.
+func (c *Client) ListOwnedReplicaSets(ctx context.Context, d *appsv1.Deployment) ([]appsv1.ReplicaSet, error) {
+	opts, err := selectorListOptions(d.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	sets, err := c.ListReplicaSets(ctx, d.Namespace, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := []appsv1.ReplicaSet{}
+	for _, rs := range sets {
+		if isControlledBy(&rs, d.UID) {
+			owned = append(owned, rs)
+		}
+	}
+	return owned, nil
+}
+
+// ListOwnedPods returns the pods matching selector that are controlled by one of owners.
+func (c *Client) ListOwnedPods(ctx context.Context, namespace string, selector *metav1.LabelSelector, owners []types.UID) ([]corev1.Pod, error) {
+	opts, err := selectorListOptions(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := c.ListPods(ctx, namespace, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := []corev1.Pod{}
+	for _, p := range pods {
+		for _, uid := range owners {
+			if isControlledBy(&p, uid) {
+				owned = append(owned, p)
+				break
+			}
+		}
+	}
+	return owned, nil
+}
+
+func selectorListOptions(selector *metav1.LabelSelector) (metav1.ListOptions, error) {
+	if selector == nil {
+		return metav1.ListOptions{}, nil
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return metav1.ListOptions{}, err
+	}
+	return metav1.ListOptions{LabelSelector: s.String()}, nil
+}
+
+func isControlledBy(obj metav1.Object, uid types.UID) bool {
+	owner := metav1.GetControllerOf(obj)
+	return owner != nil && owner.UID == uid
+}
@@ -0,0 +1,87 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Registry holds one Client per cluster, keyed by cluster name.
+type Registry struct {
+	clients     map[string]*Client
+	names       []string
+	defaultName string
+}
+
+// NewRegistry creates an empty registry, defaultName serves requests that select no cluster.
+func NewRegistry(defaultName string) *Registry {
+	return &Registry{clients: map[string]*Client{}, defaultName: defaultName}
+}
+
+// Add registers the client of a cluster, in listing order.
+func (r *Registry) Add(name string, c *Client) {
+	if _, exists := r.clients[name]; !exists {
+		r.names = append(r.names, name)
+	}
+	c.name = name
+	r.clients[name] = c
+}
+
+// Get returns the client of a cluster, the default cluster when name is empty.
+func (r *Registry) Get(name string) (*Client, bool) {
+	if name == "" {
+		name = r.defaultName
+	}
+	c, ok := r.clients[name]
+	return c, ok
+}
+
+// Names lists the clusters in registration order.
+func (r *Registry) Names() []string {
+	return append([]string{}, r.names...)
+}
+
+// Default is the name of the default cluster.
+func (r *Registry) Default() string {
+	return r.defaultName
+}
+
+// NewClientForContext creates a client from a kubeconfig file and context.
+// An empty context uses the current context, an empty kubeconfig the in-cluster config.
+func NewClientForContext(kubeconfig, kubeContext string) (*Client, error) {
+	if kubeContext == "" {
+		return NewClient(kubeconfig)
+	}
+
+	kConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+		&clientcmd.ConfigOverrides{CurrentContext: kubeContext},
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("context %s: %w", kubeContext, err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(kConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		Clientset: clientset,
+	}, nil
+}
+
+type clusterKey struct{}
+
+// WithClient selects the cluster client for the rest of a request.
+func WithClient(ctx context.Context, c *Client) context.Context {
+	return context.WithValue(ctx, clusterKey{}, c)
+}
+
+// ClientFrom returns the client selected with WithClient.
+func ClientFrom(ctx context.Context) (*Client, bool) {
+	c, ok := ctx.Value(clusterKey{}).(*Client)
+	return c, ok
+}
@@ -0,0 +1,43 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRegistry(t *testing.T) {
+	prod := &Client{Clientset: fake.NewSimpleClientset()}
+	staging := &Client{Clientset: fake.NewSimpleClientset()}
+
+	r := NewRegistry("prod")
+	r.Add("staging", staging)
+	r.Add("prod", prod)
+
+	assert.Equal(t, []string{"staging", "prod"}, r.Names())
+	assert.Equal(t, "prod", r.Default())
+
+	c, ok := r.Get("")
+	assert.True(t, ok)
+	assert.Same(t, prod, c)
+
+	c, ok = r.Get("staging")
+	assert.True(t, ok)
+	assert.Same(t, staging, c)
+	assert.Equal(t, "staging", c.Name())
+
+	_, ok = r.Get("dev")
+	assert.False(t, ok)
+}
+
+func TestWithClient(t *testing.T) {
+	_, ok := ClientFrom(context.Background())
+	assert.False(t, ok)
+
+	c := &Client{Clientset: fake.NewSimpleClientset()}
+	got, ok := ClientFrom(WithClient(context.Background(), c))
+	assert.True(t, ok)
+	assert.Same(t, c, got)
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/moemoeq/tyk-sre-app/internal/blocks"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const MetricK8sNetworkBlockExpiry = "k8s_network_block_expiry_timestamp_seconds"
+
+var networkBlockExpiryDesc = prometheus.NewDesc(MetricK8sNetworkBlockExpiry,
+	"Unix time at which a network block expires, one series per expiring block.",
+	[]string{"cluster", "block_id", "namespace_a", "namespace_b"}, nil)
+
+// collectBlocks exports the expiry of time-limited network blocks, read from their policy annotations.
+func (c *k8sCollector) collectBlocks(ctx context.Context, ch chan<- prometheus.Metric, cluster string, client *k8s.Client) {
+	policies, err := client.ListNetworkPolicies(ctx, "", metav1.ListOptions{LabelSelector: blocks.LabelBlockID})
+	if err != nil {
+		fmt.Println("failed to list network blocks for metrics", cluster, err)
+		return
+	}
+
+	for _, b := range blocks.FromPolicies(policies) {
+		if b.ExpiresAt == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(networkBlockExpiryDesc, prometheus.GaugeValue, float64(b.ExpiresAt.Unix()),
+			cluster, b.ID, b.TargetA.Namespace, b.TargetB.Namespace)
+	}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/moemoeq/tyk-sre-app/internal/blocks"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCollectBlocks(t *testing.T) {
+	policy := func(namespace, id, expiresAt string) *networkingv1.NetworkPolicy {
+		p := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{
+			Name:      "block-" + id,
+			Namespace: namespace,
+			Labels:    map[string]string{blocks.LabelBlockID: id},
+			Annotations: map[string]string{
+				blocks.AnnotationBlockTargetA: `{"namespace":"ns-a","label_selector":"app=foo"}`,
+				blocks.AnnotationBlockTargetB: `{"namespace":"ns-b","label_selector":"app=bar"}`,
+			},
+		}}
+		if expiresAt != "" {
+			p.Annotations[blocks.AnnotationBlockExpiresAt] = expiresAt
+		}
+		return p
+	}
+
+	clientset := fake.NewSimpleClientset(
+		policy("ns-a", "abc", "2024-01-01T13:00:00Z"),
+		policy("ns-b", "abc", "2024-01-01T13:00:00Z"),
+		// permanent block
+		policy("ns-a", "def", ""),
+	)
+
+	reg := prometheus.NewRegistry()
+	clusters := k8s.NewRegistry("prod")
+	clusters.Add("prod", &k8s.Client{Clientset: clientset})
+	Init(context.TODO(), reg, clusters, NamespaceFilter{})
+
+	expected := `
+# HELP k8s_network_block_expiry_timestamp_seconds Unix time at which a network block expires, one series per expiring block.
+# TYPE k8s_network_block_expiry_timestamp_seconds gauge
+k8s_network_block_expiry_timestamp_seconds{block_id="abc",cluster="prod",namespace_a="ns-a",namespace_b="ns-b"} 1.7041140e+09
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected), MetricK8sNetworkBlockExpiry)
+	assert.NoError(t, err)
+}
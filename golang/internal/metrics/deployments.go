@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	MetricK8sDeploymentHealthy             = "k8s_deployment_healthy"
+	MetricK8sDeploymentReplicasDesired     = "k8s_deployment_replicas_desired"
+	MetricK8sDeploymentReplicasReady       = "k8s_deployment_replicas_ready"
+	MetricK8sDeploymentReplicasUpdated     = "k8s_deployment_replicas_updated"
+	MetricK8sDeploymentReplicasUnavailable = "k8s_deployment_replicas_unavailable"
+	MetricK8sDeploymentUnhealthyReason     = "k8s_deployment_unhealthy_reason"
+	MetricK8sDeploymentDegraded            = "k8s_deployment_degraded"
+	MetricK8sDeploymentDegradedReason      = "k8s_deployment_degraded_reason"
+)
+
+var (
+	deploymentLabels = []string{"cluster", "namespace", "deployment"}
+
+	deploymentHealthyDesc = prometheus.NewDesc(MetricK8sDeploymentHealthy,
+		"Whether the deployment passes all health checks.", deploymentLabels, nil)
+	deploymentDesiredDesc = prometheus.NewDesc(MetricK8sDeploymentReplicasDesired,
+		"Desired replicas of the deployment.", deploymentLabels, nil)
+	deploymentReadyDesc = prometheus.NewDesc(MetricK8sDeploymentReplicasReady,
+		"Ready replicas of the deployment.", deploymentLabels, nil)
+	deploymentUpdatedDesc = prometheus.NewDesc(MetricK8sDeploymentReplicasUpdated,
+		"Updated replicas of the deployment.", deploymentLabels, nil)
+	deploymentUnavailableDesc = prometheus.NewDesc(MetricK8sDeploymentReplicasUnavailable,
+		"Unavailable replicas of the deployment.", deploymentLabels, nil)
+	deploymentReasonDesc = prometheus.NewDesc(MetricK8sDeploymentUnhealthyReason,
+		"Failing health check of the deployment, 1 per failing check.", append(deploymentLabels, "check"), nil)
+	deploymentDegradedDesc = prometheus.NewDesc(MetricK8sDeploymentDegraded,
+		"Whether the deployment is short on capacity against its HorizontalPodAutoscaler.", deploymentLabels, nil)
+	deploymentDegradedReasonDesc = prometheus.NewDesc(MetricK8sDeploymentDegradedReason,
+		"Capacity check of the deployment against its HorizontalPodAutoscaler, 1 per degraded check.", append(deploymentLabels, "check"), nil)
+)
+
+// NamespaceFilter bounds per-workload label cardinality.
+// An empty Allow list allows every namespace; Deny wins over Allow.
+type NamespaceFilter struct {
+	Allow []string
+	Deny  []string
+}
+
+func (f NamespaceFilter) Allowed(namespace string) bool {
+	if slices.Contains(f.Deny, namespace) {
+		return false
+	}
+	return len(f.Allow) == 0 || slices.Contains(f.Allow, namespace)
+}
+
+// collectDeployments exports per-deployment series from the same evaluator as /deployments.
+func (c *k8sCollector) collectDeployments(ctx context.Context, ch chan<- prometheus.Metric, cluster string, client *k8s.Client) {
+	deployments, err := client.ListDeployments(ctx, "", metav1.ListOptions{})
+	if err != nil {
+		fmt.Println("failed to list deployments for metrics", cluster, err)
+		return
+	}
+	hpas, err := client.ListHorizontalPodAutoscalers(ctx, "", metav1.ListOptions{})
+	if err != nil {
+		// health does not depend on the autoscaler, keep exporting without it
+		fmt.Println("failed to list hpas for metrics", cluster, err)
+	}
+
+	for _, d := range deployments {
+		if !c.namespaces.Allowed(d.Namespace) {
+			continue
+		}
+
+		result, _ := health.EvaluateDeploymentAutoscaled(&d, hpas)
+		desired := int32(0)
+		if d.Spec.Replicas != nil {
+			desired = *d.Spec.Replicas
+		}
+
+		ch <- prometheus.MustNewConstMetric(deploymentHealthyDesc, prometheus.GaugeValue, BoolToFloat(result.Healthy), cluster, d.Namespace, d.Name)
+		ch <- prometheus.MustNewConstMetric(deploymentDesiredDesc, prometheus.GaugeValue, float64(desired), cluster, d.Namespace, d.Name)
+		ch <- prometheus.MustNewConstMetric(deploymentReadyDesc, prometheus.GaugeValue, float64(d.Status.ReadyReplicas), cluster, d.Namespace, d.Name)
+		ch <- prometheus.MustNewConstMetric(deploymentUpdatedDesc, prometheus.GaugeValue, float64(d.Status.UpdatedReplicas), cluster, d.Namespace, d.Name)
+		ch <- prometheus.MustNewConstMetric(deploymentUnavailableDesc, prometheus.GaugeValue, float64(d.Status.UnavailableReplicas), cluster, d.Namespace, d.Name)
+
+		for _, reason := range result.Reasons {
+			ch <- prometheus.MustNewConstMetric(deploymentReasonDesc, prometheus.GaugeValue, 1, cluster, d.Namespace, d.Name, reason.Check)
+		}
+		ch <- prometheus.MustNewConstMetric(deploymentDegradedDesc, prometheus.GaugeValue, BoolToFloat(result.Degraded), cluster, d.Namespace, d.Name)
+		for _, reason := range result.DegradedReasons {
+			ch <- prometheus.MustNewConstMetric(deploymentDegradedReasonDesc, prometheus.GaugeValue, 1, cluster, d.Namespace, d.Name, reason.Check)
+		}
+	}
+}
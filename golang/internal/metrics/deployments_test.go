@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNamespaceFilter(t *testing.T) {
+	assert.True(t, NamespaceFilter{}.Allowed("default"))
+	assert.True(t, NamespaceFilter{Allow: []string{"shop"}}.Allowed("shop"))
+	assert.False(t, NamespaceFilter{Allow: []string{"shop"}}.Allowed("default"))
+	assert.False(t, NamespaceFilter{Allow: []string{"shop"}, Deny: []string{"shop"}}.Allowed("shop"))
+	assert.False(t, NamespaceFilter{Deny: []string{"kube-system"}}.Allowed("kube-system"))
+}
+
+func TestCollectDeployments(t *testing.T) {
+	replicas := int32(3)
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status: appsv1.DeploymentStatus{
+				ReadyReplicas:       2,
+				UpdatedReplicas:     3,
+				UnavailableReplicas: 1,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: "True"},
+					{Type: appsv1.DeploymentProgressing, Status: "True"},
+				},
+			},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"},
+		},
+		// scaling up to 5 while 3 are ready
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "checkout"},
+				MaxReplicas:    10,
+			},
+			Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: 3, DesiredReplicas: 5},
+		},
+	)
+
+	reg := prometheus.NewRegistry()
+	clusters := k8s.NewRegistry("prod")
+	clusters.Add("prod", &k8s.Client{Clientset: clientset})
+	Init(context.TODO(), reg, clusters, NamespaceFilter{Deny: []string{"kube-system"}})
+
+	expected := `
+# HELP k8s_deployment_healthy Whether the deployment passes all health checks.
+# TYPE k8s_deployment_healthy gauge
+k8s_deployment_healthy{cluster="prod",deployment="checkout",namespace="shop"} 0
+# HELP k8s_deployment_replicas_desired Desired replicas of the deployment.
+# TYPE k8s_deployment_replicas_desired gauge
+k8s_deployment_replicas_desired{cluster="prod",deployment="checkout",namespace="shop"} 3
+# HELP k8s_deployment_replicas_ready Ready replicas of the deployment.
+# TYPE k8s_deployment_replicas_ready gauge
+k8s_deployment_replicas_ready{cluster="prod",deployment="checkout",namespace="shop"} 2
+# HELP k8s_deployment_replicas_unavailable Unavailable replicas of the deployment.
+# TYPE k8s_deployment_replicas_unavailable gauge
+k8s_deployment_replicas_unavailable{cluster="prod",deployment="checkout",namespace="shop"} 1
+# HELP k8s_deployment_unhealthy_reason Failing health check of the deployment, 1 per failing check.
+# TYPE k8s_deployment_unhealthy_reason gauge
+k8s_deployment_unhealthy_reason{check="ready_replicas",cluster="prod",deployment="checkout",namespace="shop"} 1
+k8s_deployment_unhealthy_reason{check="unavailable_replicas",cluster="prod",deployment="checkout",namespace="shop"} 1
+# HELP k8s_deployment_degraded Whether the deployment is short on capacity against its HorizontalPodAutoscaler.
+# TYPE k8s_deployment_degraded gauge
+k8s_deployment_degraded{cluster="prod",deployment="checkout",namespace="shop"} 1
+# HELP k8s_deployment_degraded_reason Capacity check of the deployment against its HorizontalPodAutoscaler, 1 per degraded check.
+# TYPE k8s_deployment_degraded_reason gauge
+k8s_deployment_degraded_reason{check="hpa_desired_replicas",cluster="prod",deployment="checkout",namespace="shop"} 1
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		MetricK8sDeploymentHealthy,
+		MetricK8sDeploymentReplicasDesired,
+		MetricK8sDeploymentReplicasReady,
+		MetricK8sDeploymentReplicasUnavailable,
+		MetricK8sDeploymentUnhealthyReason,
+		MetricK8sDeploymentDegraded,
+		MetricK8sDeploymentDegradedReason,
+	)
+	assert.NoError(t, err)
+}
@@ -2,6 +2,8 @@ package metrics
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/moemoeq/tyk-sre-app/internal/k8s"
 	"github.com/prometheus/client_golang/prometheus"
@@ -13,10 +15,23 @@ const (
 	MetricK8sAPIServerDiscoverySuccess = "k8s_api_server_discovery_success"
 )
 
+// collectTimeout bounds the scrape of a single cluster.
+var collectTimeout = 10 * time.Second
+
+var (
+	apiServerVersionDesc = prometheus.NewDesc(MetricK8sAPIServerVersion,
+		"Kubernetes API server version.", []string{"cluster", "version"}, nil)
+	apiServerReachableDesc = prometheus.NewDesc(MetricK8sAPIServerReachable,
+		"Kubernetes API server reachability status.", []string{"cluster"}, nil)
+	apiServerDiscoveryDesc = prometheus.NewDesc(MetricK8sAPIServerDiscoverySuccess,
+		"Kubernetes API server discovery success status.", []string{"cluster"}, nil)
+)
+
 type Metrics struct{}
 
 type k8sCollector struct {
-	client *k8s.Client
+	clusters   *k8s.Registry
+	namespaces NamespaceFilter
 }
 
 func (c *k8sCollector) Describe(ch chan<- *prometheus.Desc) {
@@ -24,29 +39,49 @@ func (c *k8sCollector) Describe(ch chan<- *prometheus.Desc) {
 }
 
 func (c *k8sCollector) Collect(ch chan<- prometheus.Metric) {
-	status := c.client.CheckConnectivity(context.Background())
+	forEachCluster(c.clusters, ch, c.collectCluster)
+}
+
+// forEachCluster runs collect for every cluster concurrently, each bounded by collectTimeout,
+// so an unreachable one does not stall the rest.
+func forEachCluster(clusters *k8s.Registry, ch chan<- prometheus.Metric,
+	collect func(ctx context.Context, ch chan<- prometheus.Metric, cluster string, client *k8s.Client)) {
+	var wg sync.WaitGroup
+	for _, name := range clusters.Names() {
+		client, _ := clusters.Get(name)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+			defer cancel()
+			collect(ctx, ch, name, client)
+		}()
+	}
+	wg.Wait()
+}
+
+func (c *k8sCollector) collectCluster(ctx context.Context, ch chan<- prometheus.Metric, cluster string, client *k8s.Client) {
+	status := client.CheckConnectivity(ctx)
 
 	// 1. Version Metric
 	version := status.Version
 	if version == "" {
 		version = "unknown"
 	}
-	ch <- prometheus.MustNewConstMetric(
-		prometheus.NewDesc(MetricK8sAPIServerVersion, "Kubernetes API server version.", []string{"version"}, nil),
-		prometheus.GaugeValue, 1, version,
-	)
+	ch <- prometheus.MustNewConstMetric(apiServerVersionDesc, prometheus.GaugeValue, 1, cluster, version)
 
 	// 2. Reachability Metric
-	ch <- prometheus.MustNewConstMetric(
-		prometheus.NewDesc(MetricK8sAPIServerReachable, "Kubernetes API server reachability status.", nil, nil),
-		prometheus.GaugeValue, BoolToFloat(status.Reachability),
-	)
+	ch <- prometheus.MustNewConstMetric(apiServerReachableDesc, prometheus.GaugeValue, BoolToFloat(status.Reachability), cluster)
 
 	// 3. Discovery Metric
-	ch <- prometheus.MustNewConstMetric(
-		prometheus.NewDesc(MetricK8sAPIServerDiscoverySuccess, "Kubernetes API server discovery success status.", nil, nil),
-		prometheus.GaugeValue, BoolToFloat(status.Discovery),
-	)
+	ch <- prometheus.MustNewConstMetric(apiServerDiscoveryDesc, prometheus.GaugeValue, BoolToFloat(status.Discovery), cluster)
+
+	// 4. Per-deployment health
+	c.collectDeployments(ctx, ch, cluster, client)
+
+	// 5. Expiring network blocks
+	c.collectBlocks(ctx, ch, cluster, client)
 }
 
 func BoolToFloat(b bool) float64 {
@@ -56,8 +91,8 @@ func BoolToFloat(b bool) float64 {
 	}[b]
 }
 
-// register prometheus metrics.
-func Init(ctx context.Context, reg prometheus.Registerer, client *k8s.Client) *Metrics {
-	reg.MustRegister(&k8sCollector{client: client})
+// register prometheus metrics, series are labelled by cluster name.
+func Init(ctx context.Context, reg prometheus.Registerer, clusters *k8s.Registry, namespaces NamespaceFilter) *Metrics {
+	reg.MustRegister(&k8sCollector{clusters: clusters, namespaces: namespaces})
 	return &Metrics{}
 }
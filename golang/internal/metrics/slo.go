@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/history"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/moemoeq/tyk-sre-app/internal/slo"
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	MetricK8sDeploymentSLOTarget               = "k8s_deployment_slo_target"
+	MetricK8sDeploymentSLOAvailability         = "k8s_deployment_slo_availability"
+	MetricK8sDeploymentSLOErrorBudgetRemaining = "k8s_deployment_slo_error_budget_remaining"
+	MetricK8sDeploymentSLOBurnRate             = "k8s_deployment_slo_burn_rate"
+)
+
+var (
+	sloWindowLabels = append(append([]string{}, deploymentLabels...), "window")
+
+	sloTargetDesc = prometheus.NewDesc(MetricK8sDeploymentSLOTarget,
+		"Availability target of the deployment from its sre.tyk.io/slo-target annotation, as a ratio.", deploymentLabels, nil)
+	sloAvailabilityDesc = prometheus.NewDesc(MetricK8sDeploymentSLOAvailability,
+		"Healthy share of the observed time over the window, as a ratio.", sloWindowLabels, nil)
+	sloBudgetDesc = prometheus.NewDesc(MetricK8sDeploymentSLOErrorBudgetRemaining,
+		"Share of the error budget left over the window, as a ratio, negative once exhausted.", sloWindowLabels, nil)
+	sloBurnRateDesc = prometheus.NewDesc(MetricK8sDeploymentSLOBurnRate,
+		"Observed error rate over the allowed error rate for the window.", sloWindowLabels, nil)
+)
+
+type sloCollector struct {
+	clusters   *k8s.Registry
+	store      *history.Store
+	namespaces NamespaceFilter
+}
+
+func (c *sloCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *sloCollector) Collect(ch chan<- prometheus.Metric) {
+	forEachCluster(c.clusters, ch, c.collectCluster)
+}
+
+// collectCluster exports the same figures as /slo.
+func (c *sloCollector) collectCluster(ctx context.Context, ch chan<- prometheus.Metric, cluster string, client *k8s.Client) {
+	deployments, err := client.ListDeployments(ctx, "", metav1.ListOptions{})
+	if err != nil {
+		fmt.Println("failed to list deployments for slo metrics", cluster, err)
+		return
+	}
+
+	var allowed []appsv1.Deployment
+	for _, d := range deployments {
+		if c.namespaces.Allowed(d.Namespace) {
+			allowed = append(allowed, d)
+		}
+	}
+
+	reports, err := slo.Compute(c.store, cluster, allowed, time.Now())
+	if err != nil {
+		fmt.Println("failed to compute slo metrics", cluster, err)
+		return
+	}
+
+	for _, r := range reports {
+		if r.Target != nil {
+			ch <- prometheus.MustNewConstMetric(sloTargetDesc, prometheus.GaugeValue, *r.Target/100, cluster, r.Namespace, r.Name)
+		}
+		for _, w := range r.Windows {
+			if w.Availability != nil {
+				ch <- prometheus.MustNewConstMetric(sloAvailabilityDesc, prometheus.GaugeValue, *w.Availability/100, cluster, r.Namespace, r.Name, w.Window)
+			}
+			if w.ErrorBudgetRemaining != nil {
+				ch <- prometheus.MustNewConstMetric(sloBudgetDesc, prometheus.GaugeValue, *w.ErrorBudgetRemaining/100, cluster, r.Namespace, r.Name, w.Window)
+			}
+			if w.BurnRate != nil {
+				ch <- prometheus.MustNewConstMetric(sloBurnRateDesc, prometheus.GaugeValue, *w.BurnRate, cluster, r.Namespace, r.Name, w.Window)
+			}
+		}
+	}
+}
+
+// InitSLO registers the availability SLO gauges computed from the health history.
+func InitSLO(reg prometheus.Registerer, clusters *k8s.Registry, store *history.Store, namespaces NamespaceFilter) {
+	reg.MustRegister(&sloCollector{clusters: clusters, store: store, namespaces: namespaces})
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/history"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/moemoeq/tyk-sre-app/internal/slo"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCollectSLO(t *testing.T) {
+	store, err := history.Open(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	since := time.Now().Add(-60 * 24 * time.Hour)
+	require.NoError(t, store.Record(history.Key{Cluster: "prod", Namespace: "shop", Name: "checkout"}, history.Transition{Time: since, Healthy: true}))
+	require.NoError(t, store.Record(history.Key{Cluster: "prod", Namespace: "shop", Name: "cart"}, history.Transition{Time: since, Healthy: false}))
+	require.NoError(t, store.Record(history.Key{Cluster: "prod", Namespace: "kube-system", Name: "coredns"}, history.Transition{Time: since, Healthy: true}))
+
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name: "checkout", Namespace: "shop", Annotations: map[string]string{slo.AnnotationTarget: "99.5"},
+		}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "cart", Namespace: "shop"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"}},
+	)
+
+	reg := prometheus.NewRegistry()
+	clusters := k8s.NewRegistry("prod")
+	clusters.Add("prod", &k8s.Client{Clientset: clientset})
+	InitSLO(reg, clusters, store, NamespaceFilter{Deny: []string{"kube-system"}})
+
+	expected := `
+# HELP k8s_deployment_slo_availability Healthy share of the observed time over the window, as a ratio.
+# TYPE k8s_deployment_slo_availability gauge
+k8s_deployment_slo_availability{cluster="prod",deployment="cart",namespace="shop",window="30d"} 0
+k8s_deployment_slo_availability{cluster="prod",deployment="cart",namespace="shop",window="7d"} 0
+k8s_deployment_slo_availability{cluster="prod",deployment="checkout",namespace="shop",window="30d"} 1
+k8s_deployment_slo_availability{cluster="prod",deployment="checkout",namespace="shop",window="7d"} 1
+# HELP k8s_deployment_slo_burn_rate Observed error rate over the allowed error rate for the window.
+# TYPE k8s_deployment_slo_burn_rate gauge
+k8s_deployment_slo_burn_rate{cluster="prod",deployment="checkout",namespace="shop",window="30d"} 0
+k8s_deployment_slo_burn_rate{cluster="prod",deployment="checkout",namespace="shop",window="7d"} 0
+# HELP k8s_deployment_slo_error_budget_remaining Share of the error budget left over the window, as a ratio, negative once exhausted.
+# TYPE k8s_deployment_slo_error_budget_remaining gauge
+k8s_deployment_slo_error_budget_remaining{cluster="prod",deployment="checkout",namespace="shop",window="30d"} 1
+k8s_deployment_slo_error_budget_remaining{cluster="prod",deployment="checkout",namespace="shop",window="7d"} 1
+# HELP k8s_deployment_slo_target Availability target of the deployment from its sre.tyk.io/slo-target annotation, as a ratio.
+# TYPE k8s_deployment_slo_target gauge
+k8s_deployment_slo_target{cluster="prod",deployment="checkout",namespace="shop"} 0.995
+`
+	err = testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		MetricK8sDeploymentSLOTarget,
+		MetricK8sDeploymentSLOAvailability,
+		MetricK8sDeploymentSLOErrorBudgetRemaining,
+		MetricK8sDeploymentSLOBurnRate,
+	)
+	assert.NoError(t, err)
+}
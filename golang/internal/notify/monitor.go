@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Monitor polls one cluster and notifies API reachability and deployment health changes.
+type Monitor struct {
+	Notifier *Notifier
+	Cluster  string
+	Client   *k8s.Client
+	// Time between checks
+	Interval time.Duration
+
+	reachable *bool
+	// health of every deployment seen
+	healthy map[types.NamespacedName]bool
+}
+
+// Run checks until ctx is done.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+
+	for {
+		m.Check(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check notifies the changes since the previous check. Problems already present
+// on the first check fire too, so that receivers learn the current state, and active
+// problems are offered again on every check.
+func (m *Monitor) Check(ctx context.Context, now time.Time) {
+	status := m.Client.CheckConnectivity(ctx)
+	m.observeReachability(ctx, status, now)
+	if !status.Status {
+		// deployments cannot be listed, keep their last state
+		return
+	}
+	m.checkDeployments(ctx, now)
+}
+
+func (m *Monitor) checkDeployments(ctx context.Context, now time.Time) {
+	deployments, err := m.Client.ListDeployments(ctx, "", metav1.ListOptions{})
+	if err != nil {
+		fmt.Println("failed to list deployments for notifications", m.Cluster, err)
+		return
+	}
+	hpas, err := m.Client.ListHorizontalPodAutoscalers(ctx, "", metav1.ListOptions{})
+	if err != nil {
+		// health does not depend on the autoscaler, keep checking without it
+		fmt.Println("failed to list hpas for notifications", m.Cluster, err)
+	}
+
+	if m.healthy == nil {
+		m.healthy = map[types.NamespacedName]bool{}
+	}
+	seen := map[types.NamespacedName]bool{}
+	for _, d := range deployments {
+		key := types.NamespacedName{Namespace: d.Namespace, Name: d.Name}
+		seen[key] = true
+
+		// same evaluator as /deployments
+		result, _ := health.EvaluateDeploymentAutoscaled(&d, hpas)
+
+		// an unhealthy deployment is offered on every check, the notifier drops the duplicates
+		// and sends an event it held back for Dedup once the window is over
+		last, known := m.healthy[key]
+		m.healthy[key] = result.Healthy
+		if result.Healthy && (!known || last) {
+			continue
+		}
+
+		e := Event{Kind: KindDeploymentHealth, Cluster: m.Cluster, Namespace: d.Namespace, Name: d.Name, Time: now}
+		if result.Healthy {
+			e.Status = StatusResolved
+			e.Summary = fmt.Sprintf("deployment %s is healthy again on cluster %s", key, m.Cluster)
+		} else {
+			e.Status = StatusFiring
+			e.Summary = fmt.Sprintf("deployment %s is unhealthy on cluster %s", key, m.Cluster)
+			e.Reasons = result.Reasons
+		}
+		m.notify(ctx, e)
+	}
+
+	for key, healthy := range m.healthy {
+		if seen[key] {
+			continue
+		}
+		delete(m.healthy, key)
+		if !healthy {
+			m.notify(ctx, Event{
+				Kind: KindDeploymentHealth, Status: StatusResolved, Cluster: m.Cluster, Namespace: key.Namespace, Name: key.Name, Time: now,
+				Summary: fmt.Sprintf("deployment %s was deleted from cluster %s", key, m.Cluster),
+			})
+		}
+	}
+}
+
+func (m *Monitor) observeReachability(ctx context.Context, status k8s.ReachabilityStatus, now time.Time) {
+	last := m.reachable
+	m.reachable = &status.Status
+	if status.Status && (last == nil || *last) {
+		return
+	}
+
+	e := Event{Kind: KindReachability, Cluster: m.Cluster, Time: now}
+	if status.Status {
+		e.Status = StatusResolved
+		e.Summary = fmt.Sprintf("Kubernetes API of cluster %s is reachable again", m.Cluster)
+	} else {
+		e.Status = StatusFiring
+		e.Summary = fmt.Sprintf("Kubernetes API of cluster %s is unreachable", m.Cluster)
+		e.Message = status.Error
+	}
+	m.notify(ctx, e)
+}
+
+func (m *Monitor) notify(ctx context.Context, e Event) {
+	if err := m.Notifier.Notify(ctx, e); err != nil {
+		fmt.Println("failed to send notification", e.Key(), err)
+	}
+}
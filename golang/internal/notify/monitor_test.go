@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	testing2 "k8s.io/client-go/testing"
+)
+
+func deployment(name string, ready int32) *appsv1.Deployment {
+	replicas := int32(1)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			UpdatedReplicas: 1, ReadyReplicas: ready,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	sink, err := NewWebhookSink(srv.URL, "")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset(deployment("web", 1), deployment("api", 0))
+	m := &Monitor{Notifier: &Notifier{Sinks: []Sink{sink}}, Cluster: "prod", Client: &k8s.Client{Clientset: clientset}}
+	now := time.Now()
+
+	// api is already unhealthy, web healthy
+	m.checkDeployments(ctx, now)
+	_, err = clientset.AppsV1().Deployments("default").Update(ctx, deployment("web", 0), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, clientset.AppsV1().Deployments("default").Delete(ctx, "api", metav1.DeleteOptions{}))
+	m.checkDeployments(ctx, now.Add(time.Minute))
+	// nothing changed
+	m.checkDeployments(ctx, now.Add(2*time.Minute))
+
+	m.observeReachability(ctx, k8s.ReachabilityStatus{Status: true}, now)
+	m.observeReachability(ctx, k8s.ReachabilityStatus{Error: "reachability error: timeout"}, now.Add(time.Minute))
+	m.observeReachability(ctx, k8s.ReachabilityStatus{Status: true}, now.Add(2*time.Minute))
+
+	var got []string
+	for _, b := range rc.received() {
+		var e Event
+		require.NoError(t, json.Unmarshal([]byte(b), &e))
+		got = append(got, e.Kind+" "+e.Name+" "+e.Status)
+	}
+	assert.Equal(t, []string{
+		"deployment_health api firing",
+		"deployment_health web firing",
+		"deployment_health api resolved",
+		"reachability  firing",
+		"reachability  resolved",
+	}, got)
+}
+
+func TestMonitor_WithoutHPAs(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	sink, err := NewWebhookSink(srv.URL, "")
+	require.NoError(t, err)
+
+	clientset := fake.NewSimpleClientset(deployment("api", 0))
+	clientset.PrependReactor("list", "horizontalpodautoscalers", func(action testing2.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewNotFound(schema.GroupResource{Group: "autoscaling", Resource: "horizontalpodautoscalers"}, "")
+	})
+	m := &Monitor{Notifier: &Notifier{Sinks: []Sink{sink}}, Cluster: "prod", Client: &k8s.Client{Clientset: clientset}}
+	m.checkDeployments(context.Background(), time.Now())
+
+	assert.Len(t, rc.received(), 1, "deployments are still checked")
+}
+
+func TestMonitor_RefiresAfterDedup(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	sink, err := NewWebhookSink(srv.URL, "")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset(deployment("web", 0))
+	m := &Monitor{Notifier: &Notifier{Sinks: []Sink{sink}, Dedup: 10 * time.Minute}, Cluster: "prod", Client: &k8s.Client{Clientset: clientset}}
+	setReady := func(ready int32) {
+		_, err := clientset.AppsV1().Deployments("default").Update(ctx, deployment("web", ready), metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// flaps, then breaks again inside the dedup window and stays broken
+	m.checkDeployments(ctx, base)
+	setReady(1)
+	m.checkDeployments(ctx, base.Add(time.Minute))
+	setReady(0)
+	m.checkDeployments(ctx, base.Add(2*time.Minute))
+	m.checkDeployments(ctx, base.Add(5*time.Minute))
+	assert.Len(t, rc.received(), 2, "the re-fire is held back inside the window")
+
+	m.checkDeployments(ctx, base.Add(11*time.Minute))
+	m.checkDeployments(ctx, base.Add(12*time.Minute))
+
+	var got []string
+	for _, b := range rc.received() {
+		var e Event
+		require.NoError(t, json.Unmarshal([]byte(b), &e))
+		got = append(got, e.Status+" "+e.Time.Sub(base).String())
+	}
+	assert.Equal(t, []string{"firing 0s", "resolved 1m0s", "firing 11m0s"}, got)
+}
+
+func TestMonitor_HangingSinkDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer hanging.Close()
+	defer close(release)
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	dead, err := NewWebhookSink(hanging.URL, "")
+	require.NoError(t, err)
+	live, err := NewWebhookSink(srv.URL, "")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notifier := &Notifier{Sinks: []Sink{dead, live}, Retries: 3, Backoff: time.Second}
+	notifier.Start(ctx)
+
+	// the fake API server has no /healthz, every check fires the reachability alert
+	m := &Monitor{Notifier: notifier, Cluster: "prod", Client: &k8s.Client{Clientset: fake.NewSimpleClientset()}}
+
+	start := time.Now()
+	m.Check(ctx, start)
+	m.Check(ctx, start.Add(time.Minute))
+	assert.Less(t, time.Since(start), time.Second, "checks do not wait for the sinks")
+	assert.Eventually(t, func() bool { return len(rc.received()) == 1 }, 5*time.Second, 10*time.Millisecond,
+		"the other sink still gets the event")
+}
@@ -0,0 +1,236 @@
+// Package notify sends deployment health and API reachability changes to external sinks.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+)
+
+const (
+	KindDeploymentHealth = "deployment_health"
+	KindReachability     = "reachability"
+
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+// Event is a change worth notifying, firing when something broke and resolved when it recovered.
+type Event struct {
+	Kind      string          `json:"kind"`
+	Status    string          `json:"status"`
+	Cluster   string          `json:"cluster"`
+	Namespace string          `json:"namespace,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Summary   string          `json:"summary"`
+	Message   string          `json:"message,omitempty"`
+	Reasons   []health.Reason `json:"reasons,omitempty"`
+	// When the change was observed, a resolved event also carries when it started firing
+	Time     time.Time `json:"time"`
+	StartsAt time.Time `json:"starts_at"`
+}
+
+// Key identifies the alert an event belongs to, shared by its firing and resolved events.
+func (e Event) Key() string {
+	return e.Kind + "/" + e.Cluster + "/" + e.Namespace + "/" + e.Name
+}
+
+// Sink delivers events to one receiver.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, e Event) error
+}
+
+// Repeater is a Sink that wants the firing event of an active alert on every check,
+// not only when it starts firing.
+type Repeater interface {
+	Repeat() bool
+}
+
+// StatusError is a non-2xx response of a receiver. Only 429 and 5xx are retried.
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("receiver responded %d %s", e.Code, http.StatusText(e.Code))
+}
+
+func (e *StatusError) retryable() bool {
+	return e.Code == http.StatusTooManyRequests || e.Code >= 500
+}
+
+// alert is the delivery state of an alert key.
+type alert struct {
+	status   string
+	firedAt  time.Time
+	startsAt time.Time
+}
+
+// Notifier fans events out to its sinks.
+type Notifier struct {
+	Sinks []Sink
+	// A firing event is dropped when the same alert fired less than Dedup ago, which silences flapping.
+	// Monitor offers it again on its next checks, so a problem that persists fires once Dedup is over.
+	Dedup time.Duration
+	// Failed deliveries are retried Retries times, waiting Backoff then doubling
+	Retries int
+	Backoff time.Duration
+	// Once started, events wait in a queue of QueueSize per sink, defaultQueueSize when 0
+	QueueSize int
+
+	mu     sync.Mutex
+	alerts map[string]alert
+	queues []chan Event
+}
+
+const defaultQueueSize = 100
+
+// errQueueFull is returned for a sink whose queue has no room left, the event is dropped.
+var errQueueFull = errors.New("notification queue is full")
+
+// Start delivers the events of later Notify calls from one goroutine per sink until ctx is done,
+// so that a slow or dead sink delays neither the caller nor the other sinks.
+func (n *Notifier) Start(ctx context.Context) {
+	size := n.QueueSize
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	queues := make([]chan Event, len(n.Sinks))
+	for i, sink := range n.Sinks {
+		queues[i] = make(chan Event, size)
+		go n.deliver(ctx, sink, queues[i])
+	}
+
+	n.mu.Lock()
+	n.queues = queues
+	n.mu.Unlock()
+}
+
+func (n *Notifier) deliver(ctx context.Context, sink Sink, queue <-chan Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-queue:
+			if err := n.send(ctx, sink, e); err != nil {
+				fmt.Println("failed to send notification", sink.Name(), e.Key(), err)
+			}
+		}
+	}
+}
+
+// Notify delivers e to every sink, returns the errors of the sinks that failed after retries.
+// Once started it only queues e and returns the errors of the sinks whose queue is full.
+// A resolved event is only sent for an alert whose firing event was sent, a firing event of
+// an alert already firing only to the sinks that are Repeaters.
+func (n *Notifier) Notify(ctx context.Context, e Event) error {
+	send, repeat := n.admit(&e)
+	if !send {
+		return nil
+	}
+
+	n.mu.Lock()
+	queues := n.queues
+	n.mu.Unlock()
+	if queues != nil {
+		var errs []error
+		for i, sink := range n.Sinks {
+			if !wants(sink, repeat) {
+				continue
+			}
+			select {
+			case queues[i] <- e:
+			default:
+				errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), errQueueFull))
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(n.Sinks))
+	for i, sink := range n.Sinks {
+		if !wants(sink, repeat) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := n.send(ctx, sink, e); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", sink.Name(), err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// wants reports whether sink takes an event, repeat is set for a firing event of an alert already firing.
+func wants(sink Sink, repeat bool) bool {
+	r, ok := sink.(Repeater)
+	return !repeat || (ok && r.Repeat())
+}
+
+// admit applies deduplication and records the alert state, repeat is set for a firing event
+// of an alert already firing.
+func (n *Notifier) admit(e *Event) (send, repeat bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.alerts == nil {
+		n.alerts = map[string]alert{}
+	}
+
+	key := e.Key()
+	last, known := n.alerts[key]
+	switch e.Status {
+	case StatusFiring:
+		if known && last.status == StatusFiring {
+			e.StartsAt = last.startsAt
+			return true, true
+		}
+		if known && e.Time.Sub(last.firedAt) < n.Dedup {
+			return false, false
+		}
+		if e.StartsAt.IsZero() {
+			e.StartsAt = e.Time
+		}
+		n.alerts[key] = alert{status: StatusFiring, firedAt: e.Time, startsAt: e.StartsAt}
+	case StatusResolved:
+		if !known || last.status != StatusFiring {
+			return false, false
+		}
+		e.StartsAt = last.startsAt
+		n.alerts[key] = alert{status: StatusResolved, firedAt: last.firedAt}
+	default:
+		return false, false
+	}
+	return true, false
+}
+
+func (n *Notifier) send(ctx context.Context, sink Sink, e Event) error {
+	backoff := n.Backoff
+	for attempt := 0; ; attempt++ {
+		err := sink.Send(ctx, e)
+		if err == nil {
+			return nil
+		}
+
+		var statusErr *StatusError
+		if attempt >= n.Retries || (errors.As(err, &statusErr) && !statusErr.retryable()) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
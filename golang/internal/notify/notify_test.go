@@ -0,0 +1,178 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver records request bodies and answers with the queued status codes, then 200.
+type receiver struct {
+	mu     sync.Mutex
+	codes  []int
+	bodies []string
+	paths  []string
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	rc.bodies = append(rc.bodies, string(body))
+	rc.paths = append(rc.paths, r.URL.Path)
+	if len(rc.codes) > 0 {
+		w.WriteHeader(rc.codes[0])
+		rc.codes = rc.codes[1:]
+	}
+}
+
+func (rc *receiver) received() []string {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]string{}, rc.bodies...)
+}
+
+func event(status string, at time.Time) Event {
+	return Event{Kind: KindDeploymentHealth, Status: status, Cluster: "prod", Namespace: "default", Name: "web", Summary: "deployment default/web", Time: at}
+}
+
+func TestNotifier_DedupAndResolved(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	sink, err := NewWebhookSink(srv.URL, "")
+	require.NoError(t, err)
+	n := &Notifier{Sinks: []Sink{sink}, Dedup: 10 * time.Minute}
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// resolved without a firing event is not sent
+	require.NoError(t, n.Notify(ctx, event(StatusResolved, base)))
+	require.NoError(t, n.Notify(ctx, event(StatusFiring, base)))
+	// still firing
+	require.NoError(t, n.Notify(ctx, event(StatusFiring, base.Add(time.Minute))))
+	require.NoError(t, n.Notify(ctx, event(StatusResolved, base.Add(2*time.Minute))))
+	// flapping inside the dedup window, neither event is sent
+	require.NoError(t, n.Notify(ctx, event(StatusFiring, base.Add(3*time.Minute))))
+	require.NoError(t, n.Notify(ctx, event(StatusResolved, base.Add(4*time.Minute))))
+	// past the window
+	require.NoError(t, n.Notify(ctx, event(StatusFiring, base.Add(time.Hour))))
+
+	bodies := rc.received()
+	require.Len(t, bodies, 3)
+	var statuses []string
+	for _, b := range bodies {
+		var e Event
+		require.NoError(t, json.Unmarshal([]byte(b), &e))
+		statuses = append(statuses, e.Status)
+	}
+	assert.Equal(t, []string{StatusFiring, StatusResolved, StatusFiring}, statuses)
+
+	var resolved Event
+	require.NoError(t, json.Unmarshal([]byte(bodies[1]), &resolved))
+	assert.Equal(t, base, resolved.StartsAt)
+}
+
+func TestNotifier_RepeatsToAlertmanager(t *testing.T) {
+	hooks, alerts := &receiver{}, &receiver{}
+	hookSrv, alertSrv := httptest.NewServer(hooks), httptest.NewServer(alerts)
+	defer hookSrv.Close()
+	defer alertSrv.Close()
+
+	webhook, err := NewWebhookSink(hookSrv.URL, "")
+	require.NoError(t, err)
+	alertmanager, err := NewAlertmanagerSink(alertSrv.URL, "")
+	require.NoError(t, err)
+	n := &Notifier{Sinks: []Sink{webhook, alertmanager}}
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, n.Notify(ctx, event(StatusFiring, base.Add(time.Duration(i)*time.Minute))))
+	}
+	require.NoError(t, n.Notify(ctx, event(StatusResolved, base.Add(10*time.Minute))))
+
+	assert.Len(t, hooks.received(), 2, "webhooks only get the changes")
+	posted := alerts.received()
+	require.Len(t, posted, 4)
+	for i, b := range posted {
+		var a []postableAlert
+		require.NoError(t, json.Unmarshal([]byte(b), &a))
+		require.Len(t, a, 1)
+		assert.True(t, base.Equal(a[0].StartsAt), "re-posts keep the start")
+		assert.Equal(t, i == 3, a[0].EndsAt != nil)
+	}
+}
+
+func TestNotifier_Retries(t *testing.T) {
+	tests := []struct {
+		name     string
+		codes    []int
+		attempts int
+		err      bool
+	}{
+		{"Recovers after server errors", []int{500, 503}, 3, false},
+		{"Gives up after retries", []int{500, 500, 500, 500}, 3, true},
+		{"Client errors are not retried", []int{400}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &receiver{codes: tt.codes}
+			srv := httptest.NewServer(rc)
+			defer srv.Close()
+
+			sink, err := NewWebhookSink(srv.URL, "")
+			require.NoError(t, err)
+			n := &Notifier{Sinks: []Sink{sink}, Retries: 2, Backoff: time.Millisecond}
+
+			err = n.Notify(context.Background(), event(StatusFiring, time.Now()))
+			assert.Equal(t, tt.err, err != nil)
+			assert.Len(t, rc.received(), tt.attempts)
+		})
+	}
+}
+
+func TestSinks(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	webhook, err := NewWebhookSink(srv.URL+"/hook", `{"alert":"{{.Status}} {{.Name}}"}`)
+	require.NoError(t, err)
+	slack, err := NewSlackSink(srv.URL+"/slack", "")
+	require.NoError(t, err)
+	alertmanager, err := NewAlertmanagerSink(srv.URL+"/", "")
+	require.NoError(t, err)
+
+	_, err = NewSlackSink(srv.URL, "{{.Status")
+	assert.Error(t, err)
+
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	resolved := event(StatusResolved, base.Add(time.Hour))
+	resolved.StartsAt = base
+
+	require.NoError(t, webhook.Send(ctx, resolved))
+	require.NoError(t, slack.Send(ctx, resolved))
+	require.NoError(t, alertmanager.Send(ctx, resolved))
+
+	assert.Equal(t, []string{"/hook", "/slack", "/api/v2/alerts"}, rc.paths)
+	bodies := rc.received()
+	assert.JSONEq(t, `{"alert":"resolved web"}`, bodies[0])
+	assert.JSONEq(t, `{"text":":white_check_mark: RESOLVED deployment default/web"}`, bodies[1])
+	assert.JSONEq(t, `[{
+		"labels": {"alertname": "DeploymentUnhealthy", "cluster": "prod", "namespace": "default", "deployment": "web", "severity": "warning"},
+		"annotations": {"summary": "deployment default/web"},
+		"startsAt": "2024-01-01T00:00:00Z",
+		"endsAt": "2024-01-01T01:00:00Z"
+	}]`, bodies[2])
+}
@@ -0,0 +1,221 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Default templates, rendered with an Event.
+const (
+	DefaultSlackTemplate = `{{if eq .Status "resolved"}}:white_check_mark: RESOLVED{{else}}:rotating_light: FIRING{{end}} {{.Summary}}` +
+		`{{if .Message}}
+{{.Message}}{{end}}{{range .Reasons}}
+• {{.Check}}: {{.Observed}} (expected {{.Expected}}){{end}}`
+	DefaultAlertmanagerTemplate = `{{.Summary}}`
+)
+
+// sendTimeout bounds a single delivery attempt.
+var sendTimeout = 10 * time.Second
+
+// WebhookSink posts the event as JSON, or the rendered template when set.
+type WebhookSink struct {
+	URL      string
+	Template *template.Template
+	Client   *http.Client
+}
+
+// SlackSink posts a Slack incoming webhook message with the rendered template as text.
+type SlackSink struct {
+	URL      string
+	Template *template.Template
+	Client   *http.Client
+}
+
+// AlertmanagerSink posts the event as an alert to the Alertmanager v2 API, the template renders the summary.
+// Resolved events end the alert. Alertmanager resolves an alert that is not posted again within its
+// resolve_timeout, so active alerts are re-posted on every check (see Repeater).
+type AlertmanagerSink struct {
+	URL      string
+	Template *template.Template
+	Client   *http.Client
+}
+
+// NewWebhookSink creates a webhook sink, an empty tmpl posts the event itself.
+func NewWebhookSink(webhookURL, tmpl string) (*WebhookSink, error) {
+	s := &WebhookSink{URL: webhookURL, Client: &http.Client{Timeout: sendTimeout}}
+	if tmpl == "" {
+		return s, nil
+	}
+
+	t, err := template.New("webhook").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("webhook template: %w", err)
+	}
+	s.Template = t
+	return s, nil
+}
+
+// NewSlackSink creates a Slack sink, an empty tmpl uses DefaultSlackTemplate.
+func NewSlackSink(webhookURL, tmpl string) (*SlackSink, error) {
+	t, err := parseTemplate("slack", tmpl, DefaultSlackTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return &SlackSink{URL: webhookURL, Template: t, Client: &http.Client{Timeout: sendTimeout}}, nil
+}
+
+// NewAlertmanagerSink creates an Alertmanager sink from its base URL, an empty tmpl uses DefaultAlertmanagerTemplate.
+func NewAlertmanagerSink(baseURL, tmpl string) (*AlertmanagerSink, error) {
+	t, err := parseTemplate("alertmanager", tmpl, DefaultAlertmanagerTemplate)
+	if err != nil {
+		return nil, err
+	}
+	alertsURL := strings.TrimSuffix(baseURL, "/")
+	if !strings.HasSuffix(alertsURL, "/api/v2/alerts") {
+		alertsURL += "/api/v2/alerts"
+	}
+	return &AlertmanagerSink{URL: alertsURL, Template: t, Client: &http.Client{Timeout: sendTimeout}}, nil
+}
+
+func (s *WebhookSink) Name() string { return "webhook " + host(s.URL) }
+
+func (s *WebhookSink) Send(ctx context.Context, e Event) error {
+	if s.Template == nil {
+		body, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		return post(ctx, s.Client, s.URL, body)
+	}
+
+	body, err := render(s.Template, e)
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.Client, s.URL, []byte(body))
+}
+
+func (s *SlackSink) Name() string { return "slack" }
+
+func (s *SlackSink) Send(ctx context.Context, e Event) error {
+	text, err := render(s.Template, e)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.Client, s.URL, body)
+}
+
+// postableAlert is the subset of the Alertmanager v2 postableAlert this sink sets.
+type postableAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      *time.Time        `json:"endsAt,omitempty"`
+}
+
+func (s *AlertmanagerSink) Name() string { return "alertmanager " + host(s.URL) }
+
+func (s *AlertmanagerSink) Repeat() bool { return true }
+
+func (s *AlertmanagerSink) Send(ctx context.Context, e Event) error {
+	summary, err := render(s.Template, e)
+	if err != nil {
+		return err
+	}
+
+	a := postableAlert{
+		Labels: map[string]string{
+			"alertname": alertName(e.Kind),
+			"cluster":   e.Cluster,
+			"severity":  "warning",
+		},
+		Annotations: map[string]string{"summary": summary},
+		StartsAt:    e.StartsAt,
+	}
+	if e.Namespace != "" {
+		a.Labels["namespace"] = e.Namespace
+		a.Labels["deployment"] = e.Name
+	}
+	if e.Message != "" {
+		a.Annotations["description"] = e.Message
+	}
+	if e.Status == StatusResolved {
+		a.EndsAt = &e.Time
+	}
+
+	body, err := json.Marshal([]postableAlert{a})
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.Client, s.URL, body)
+}
+
+func alertName(kind string) string {
+	switch kind {
+	case KindDeploymentHealth:
+		return "DeploymentUnhealthy"
+	case KindReachability:
+		return "KubernetesAPIUnreachable"
+	default:
+		return kind
+	}
+}
+
+// host keeps credentials in paths and queries out of logs.
+func host(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		return u.Host
+	}
+	return ""
+}
+
+func parseTemplate(name, tmpl, fallback string) (*template.Template, error) {
+	if tmpl == "" {
+		tmpl = fallback
+	}
+	t, err := template.New(name).Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("%s template: %w", name, err)
+	}
+	return t, nil
+}
+
+func render(t *template.Template, e Event) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, e); err != nil {
+		return "", fmt.Errorf("%s template: %w", t.Name(), err)
+	}
+	return buf.String(), nil
+}
+
+func post(ctx context.Context, client *http.Client, target string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return &StatusError{Code: res.StatusCode}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package rollout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/health"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// RestartedAtAnnotation is the pod template annotation used by `kubectl rollout restart`.
+const RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// Actions on /deployments/{namespace}/{name}/{action}.
+const (
+	ActionRestart  = "restart"
+	ActionRollback = "rollback"
+	ActionPause    = "pause"
+	ActionResume   = "resume"
+)
+
+// RestartPatch is a strategic merge patch triggering a new rollout.
+func RestartPatch(now time.Time) []byte {
+	patch := map[string]any{
+		"spec": map[string]any{
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"annotations": map[string]string{RestartedAtAnnotation: now.Format(time.RFC3339)},
+				},
+			},
+		},
+	}
+	data, _ := json.Marshal(patch)
+	return data
+}
+
+// PausePatch is a strategic merge patch pausing or resuming a rollout.
+func PausePatch(paused bool) []byte {
+	data, _ := json.Marshal(map[string]any{"spec": map[string]any{"paused": paused}})
+	return data
+}
+
+// RollbackPatch is a JSON patch restoring the pod template of rs.
+func RollbackPatch(rs *appsv1.ReplicaSet) ([]byte, error) {
+	return json.Marshal([]map[string]any{
+		{"op": "replace", "path": "/spec/template", "value": StripTemplateHash(rs.Spec.Template)},
+	})
+}
+
+// PreviousRevision returns the ReplicaSet of the highest revision below current, nil if none.
+func PreviousRevision(replicaSets []appsv1.ReplicaSet, current int64) *appsv1.ReplicaSet {
+	var prev *appsv1.ReplicaSet
+	for i := range replicaSets {
+		rev := RevisionOf(&replicaSets[i])
+		if rev < current && (prev == nil || rev > RevisionOf(prev)) {
+			prev = &replicaSets[i]
+		}
+	}
+	return prev
+}
+
+// Status is the rollout status returned by every action.
+type Status struct {
+	Action             string          `json:"action"`
+	Namespace          string          `json:"namespace"`
+	Name               string          `json:"name"`
+	Revision           string          `json:"revision,omitempty"`
+	Generation         int64           `json:"generation"`
+	ObservedGeneration int64           `json:"observed_generation"`
+	Paused             bool            `json:"paused"`
+	Complete           bool            `json:"complete"`
+	Health             bool            `json:"health"`
+	HealthReasons      []health.Reason `json:"health_reasons"`
+	TimedOut           bool            `json:"timed_out,omitempty"`
+}
+
+// NewStatus evaluates d with the deployment health rules.
+// Complete additionally requires the controller to have observed the latest spec.
+func NewStatus(action string, d *appsv1.Deployment) Status {
+	result := health.EvaluateDeployment(d)
+	return Status{
+		Action:             action,
+		Namespace:          d.Namespace,
+		Name:               d.Name,
+		Revision:           d.Annotations[RevisionAnnotation],
+		Generation:         d.Generation,
+		ObservedGeneration: d.Status.ObservedGeneration,
+		Paused:             d.Spec.Paused,
+		Complete:           result.Healthy && d.Status.ObservedGeneration >= d.Generation,
+		Health:             result.Healthy,
+		HealthReasons:      result.Reasons,
+	}
+}
+
+// Wait polls get until the rollout is complete or ctx is done.
+// On timeout the last observed status is returned with TimedOut set.
+func Wait(ctx context.Context, action string, interval time.Duration, get func(ctx context.Context) (*appsv1.Deployment, error)) (Status, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var status Status
+	for {
+		d, err := get(ctx)
+		if err != nil && ctx.Err() == nil {
+			return status, err
+		}
+		if err == nil {
+			status = NewStatus(action, d)
+			if status.Complete {
+				return status, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			status.TimedOut = true
+			return status, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ValidateRollback rejects rollbacks to the template currently running.
+func ValidateRollback(d *appsv1.Deployment, rs *appsv1.ReplicaSet) error {
+	if rs == nil {
+		return fmt.Errorf("no previous revision to roll back to")
+	}
+	current := d.Spec.Template.DeepCopy()
+	target := StripTemplateHash(rs.Spec.Template)
+	if equalTemplates(*current, target) {
+		return fmt.Errorf("revision %d is already the current pod template", RevisionOf(rs))
+	}
+	return nil
+}
+
+func equalTemplates(a, b corev1.PodTemplateSpec) bool {
+	changes, err := Diff(a, b)
+	return err == nil && len(changes) == 0
+}
@@ -0,0 +1,92 @@
+package rollout
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRestartPatch(t *testing.T) {
+	now := time.Date(2026, 10, 17, 3, 10, 0, 0, time.UTC)
+
+	var patch map[string]any
+	assert.NoError(t, json.Unmarshal(RestartPatch(now), &patch))
+	annotations := patch["spec"].(map[string]any)["template"].(map[string]any)["metadata"].(map[string]any)["annotations"].(map[string]any)
+	assert.Equal(t, "2026-10-17T03:10:00Z", annotations[RestartedAtAnnotation])
+}
+
+func TestPreviousRevision(t *testing.T) {
+	sets := []appsv1.ReplicaSet{
+		replicaSet("web-a", "1", "a"),
+		replicaSet("web-c", "3", "c"),
+		replicaSet("web-b", "2", "b"),
+	}
+
+	assert.Equal(t, "web-b", PreviousRevision(sets, 3).Name)
+	assert.Equal(t, "web-a", PreviousRevision(sets, 2).Name)
+	assert.Nil(t, PreviousRevision(sets, 1))
+}
+
+func TestValidateRollback(t *testing.T) {
+	rs := replicaSet("web-a", "1", "a")
+	rs.Spec.Template.Spec.Containers = []corev1.Container{{Name: "app", Image: "web:1.0"}}
+
+	d := &appsv1.Deployment{}
+	d.Spec.Template.Spec.Containers = []corev1.Container{{Name: "app", Image: "web:1.1"}}
+	assert.NoError(t, ValidateRollback(d, &rs))
+
+	d.Spec.Template.Spec.Containers[0].Image = "web:1.0"
+	assert.Error(t, ValidateRollback(d, &rs))
+	assert.Error(t, ValidateRollback(d, nil))
+}
+
+func TestWait(t *testing.T) {
+	replicas := int32(1)
+	d := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &replicas}}
+	d.Generation = 2
+	d.Status.ObservedGeneration = 1
+
+	calls := 0
+	get := func(ctx context.Context) (*appsv1.Deployment, error) {
+		calls++
+		if calls == 3 {
+			// controller caught up and rollout finished
+			d.Status = appsv1.DeploymentStatus{
+				ObservedGeneration: 2,
+				ReadyReplicas:      1,
+				UpdatedReplicas:    1,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+					{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+				},
+			}
+		}
+		return d.DeepCopy(), nil
+	}
+
+	status, err := Wait(context.Background(), ActionRestart, time.Millisecond, get)
+	assert.NoError(t, err)
+	assert.True(t, status.Complete)
+	assert.False(t, status.TimedOut)
+	assert.Equal(t, 3, calls)
+}
+
+func TestWait_Timeout(t *testing.T) {
+	d := &appsv1.Deployment{}
+	d.Generation = 2
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	status, err := Wait(ctx, ActionRestart, time.Millisecond, func(ctx context.Context) (*appsv1.Deployment, error) {
+		return d, nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, status.TimedOut)
+	assert.False(t, status.Complete)
+}
@@ -0,0 +1,132 @@
+package rollout
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Change operations.
+const (
+	OpAdded   = "added"
+	OpRemoved = "removed"
+	OpChanged = "changed"
+)
+
+// Change is a single difference between two pod templates.
+// Path is dot separated, list items use [index], e.g. spec.containers[0].image
+type Change struct {
+	Path string `json:"path"`
+	Op   string `json:"op"`
+	From any    `json:"from,omitempty"`
+	To   any    `json:"to,omitempty"`
+}
+
+// TemplateDiff compares the pod templates of two ReplicaSets,
+// ignoring the pod-template-hash label the controller adds to each.
+func TemplateDiff(from, to *appsv1.ReplicaSet) ([]Change, error) {
+	return Diff(StripTemplateHash(from.Spec.Template), StripTemplateHash(to.Spec.Template))
+}
+
+// StripTemplateHash returns a copy of template without the pod-template-hash label.
+func StripTemplateHash(template corev1.PodTemplateSpec) corev1.PodTemplateSpec {
+	t := *template.DeepCopy()
+	delete(t.Labels, PodTemplateHashLabel)
+	return t
+}
+
+// Diff returns the structural differences between the JSON forms of a and b.
+func Diff(a, b any) ([]Change, error) {
+	av, err := toJSONValue(a)
+	if err != nil {
+		return nil, err
+	}
+	bv, err := toJSONValue(b)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []Change{}
+	diffValue("", av, bv, &changes)
+	return changes, nil
+}
+
+func toJSONValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func diffValue(path string, a, b any, changes *[]Change) {
+	switch av := a.(type) {
+	case map[string]any:
+		if bv, ok := b.(map[string]any); ok {
+			diffMap(path, av, bv, changes)
+			return
+		}
+	case []any:
+		if bv, ok := b.([]any); ok {
+			diffSlice(path, av, bv, changes)
+			return
+		}
+	}
+
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		*changes = append(*changes, Change{Path: path, Op: OpChanged, From: a, To: b})
+	}
+}
+
+func diffMap(path string, a, b map[string]any, changes *[]Change) {
+	keys := map[string]struct{}{}
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		p := k
+		if path != "" {
+			p = path + "." + k
+		}
+		av, inA := a[k]
+		bv, inB := b[k]
+		switch {
+		case !inA:
+			*changes = append(*changes, Change{Path: p, Op: OpAdded, To: bv})
+		case !inB:
+			*changes = append(*changes, Change{Path: p, Op: OpRemoved, From: av})
+		default:
+			diffValue(p, av, bv, changes)
+		}
+	}
+}
+
+func diffSlice(path string, a, b []any, changes *[]Change) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(a):
+			*changes = append(*changes, Change{Path: p, Op: OpAdded, To: b[i]})
+		case i >= len(b):
+			*changes = append(*changes, Change{Path: p, Op: OpRemoved, From: a[i]})
+		default:
+			diffValue(p, a[i], b[i], changes)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package rollout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestTemplateDiff(t *testing.T) {
+	from := replicaSet("web-a", "1", "a")
+	from.Spec.Template.Labels = map[string]string{"app": "web", PodTemplateHashLabel: "a"}
+	from.Spec.Template.Spec.Containers = []corev1.Container{
+		{Name: "app", Image: "web:1.0", Env: []corev1.EnvVar{{Name: "MODE", Value: "a"}}},
+	}
+
+	to := replicaSet("web-b", "2", "b")
+	to.Spec.Template.Labels = map[string]string{"app": "web", PodTemplateHashLabel: "b"}
+	to.Spec.Template.Spec.Containers = []corev1.Container{
+		{Name: "app", Image: "web:1.1"},
+		{Name: "sidecar", Image: "proxy:2"},
+	}
+
+	changes, err := TemplateDiff(&from, &to)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: "spec.containers[0].env", Op: OpRemoved, From: []any{map[string]any{"name": "MODE", "value": "a"}}},
+		{Path: "spec.containers[0].image", Op: OpChanged, From: "web:1.0", To: "web:1.1"},
+		{Path: "spec.containers[1]", Op: OpAdded, To: map[string]any{"name": "sidecar", "image": "proxy:2", "resources": map[string]any{}}},
+	}, changes)
+}
+
+func TestDiff_Equal(t *testing.T) {
+	changes, err := Diff(map[string]string{"a": "b"}, map[string]string{"a": "b"})
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
@@ -0,0 +1,68 @@
+package rollout
+
+import (
+	"sort"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Annotations and labels maintained by the deployment controller.
+const (
+	RevisionAnnotation    = "deployment.kubernetes.io/revision"
+	ChangeCauseAnnotation = "kubernetes.io/change-cause"
+	PodTemplateHashLabel  = appsv1.DefaultDeploymentUniqueLabelKey
+)
+
+// Revision is one entry of a deployment rollout history.
+type Revision struct {
+	Revision        int64       `json:"revision"`
+	ReplicaSet      string      `json:"replica_set"`
+	ChangeCause     string      `json:"change_cause,omitempty"`
+	CreatedAt       metav1.Time `json:"created_at"`
+	PodTemplateHash string      `json:"pod_template_hash,omitempty"`
+	Replicas        int32       `json:"replicas"`
+}
+
+// RevisionOf returns the revision of a ReplicaSet, 0 if missing or malformed.
+func RevisionOf(rs *appsv1.ReplicaSet) int64 {
+	v, err := strconv.ParseInt(rs.Annotations[RevisionAnnotation], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// History orders the ReplicaSets of a deployment by revision, oldest first.
+func History(replicaSets []appsv1.ReplicaSet) []Revision {
+	history := make([]Revision, 0, len(replicaSets))
+	for _, rs := range replicaSets {
+		rev := Revision{
+			Revision:        RevisionOf(&rs),
+			ReplicaSet:      rs.Name,
+			ChangeCause:     rs.Annotations[ChangeCauseAnnotation],
+			CreatedAt:       rs.CreationTimestamp,
+			PodTemplateHash: rs.Labels[PodTemplateHashLabel],
+		}
+		if rs.Spec.Replicas != nil {
+			rev.Replicas = *rs.Spec.Replicas
+		}
+		history = append(history, rev)
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Revision < history[j].Revision
+	})
+	return history
+}
+
+// FindRevision returns the ReplicaSet of a revision, nil if not found.
+func FindRevision(replicaSets []appsv1.ReplicaSet, revision int64) *appsv1.ReplicaSet {
+	for i := range replicaSets {
+		if RevisionOf(&replicaSets[i]) == revision {
+			return &replicaSets[i]
+		}
+	}
+	return nil
+}
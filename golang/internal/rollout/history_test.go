@@ -0,0 +1,47 @@
+package rollout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func replicaSet(name, revision, hash string) appsv1.ReplicaSet {
+	return appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)),
+			Annotations: map[string]string{
+				RevisionAnnotation:    revision,
+				ChangeCauseAnnotation: "deploy " + revision,
+			},
+			Labels: map[string]string{PodTemplateHashLabel: hash},
+		},
+	}
+}
+
+func TestHistory(t *testing.T) {
+	history := History([]appsv1.ReplicaSet{
+		replicaSet("web-c", "10", "c"),
+		replicaSet("web-a", "2", "a"),
+		replicaSet("web-b", "9", "b"),
+	})
+
+	assert.Len(t, history, 3)
+	assert.Equal(t, int64(2), history[0].Revision)
+	assert.Equal(t, int64(9), history[1].Revision)
+	assert.Equal(t, int64(10), history[2].Revision)
+	assert.Equal(t, "web-c", history[2].ReplicaSet)
+	assert.Equal(t, "deploy 10", history[2].ChangeCause)
+	assert.Equal(t, "c", history[2].PodTemplateHash)
+}
+
+func TestFindRevision(t *testing.T) {
+	sets := []appsv1.ReplicaSet{replicaSet("web-a", "1", "a"), replicaSet("web-b", "2", "b")}
+
+	assert.Equal(t, "web-b", FindRevision(sets, 2).Name)
+	assert.Nil(t, FindRevision(sets, 3))
+}
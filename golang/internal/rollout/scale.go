@@ -0,0 +1,44 @@
+package rollout
+
+import (
+	"fmt"
+	"slices"
+)
+
+// DefaultNamespace is the guard key applied to namespaces without their own limit.
+const DefaultNamespace = "*"
+
+// ScaleGuard holds the guardrails of the scale endpoint.
+type ScaleGuard struct {
+	// MinReplicas and MaxReplicas by namespace, DefaultNamespace for the rest
+	MinReplicas map[string]int
+	MaxReplicas map[string]int
+
+	// Namespaces that can never be scaled to zero
+	Protected []string
+}
+
+// Check rejects a replica count outside the guardrails of namespace.
+func (g ScaleGuard) Check(namespace string, replicas int32) error {
+	if replicas < 0 {
+		return fmt.Errorf("replicas must not be negative")
+	}
+	if replicas == 0 && (slices.Contains(g.Protected, namespace) || slices.Contains(g.Protected, DefaultNamespace)) {
+		return fmt.Errorf("namespace %s is protected from scaling to zero", namespace)
+	}
+	if min, ok := limit(g.MinReplicas, namespace); ok && int(replicas) < min {
+		return fmt.Errorf("replicas %d below minimum %d for namespace %s", replicas, min, namespace)
+	}
+	if max, ok := limit(g.MaxReplicas, namespace); ok && int(replicas) > max {
+		return fmt.Errorf("replicas %d above maximum %d for namespace %s", replicas, max, namespace)
+	}
+	return nil
+}
+
+func limit(limits map[string]int, namespace string) (int, bool) {
+	if v, ok := limits[namespace]; ok {
+		return v, true
+	}
+	v, ok := limits[DefaultNamespace]
+	return v, ok
+}
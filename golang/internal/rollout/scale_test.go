@@ -0,0 +1,26 @@
+package rollout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaleGuard(t *testing.T) {
+	guard := ScaleGuard{
+		MinReplicas: map[string]int{"shop": 2, DefaultNamespace: 0},
+		MaxReplicas: map[string]int{DefaultNamespace: 10},
+		Protected:   []string{"prod"},
+	}
+
+	assert.NoError(t, guard.Check("shop", 3))
+	assert.NoError(t, guard.Check("dev", 0))
+	assert.Error(t, guard.Check("shop", 1))
+	assert.Error(t, guard.Check("dev", 11))
+	assert.Error(t, guard.Check("prod", 0))
+	assert.NoError(t, guard.Check("prod", 1))
+	assert.Error(t, guard.Check("dev", -1))
+
+	// no limits configured
+	assert.NoError(t, ScaleGuard{}.Check("dev", 100))
+}
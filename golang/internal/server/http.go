@@ -11,7 +11,14 @@ import (
 )
 
 func New(ctx context.Context, addr string, apiV1 *v1.API) *http.Server {
-	metrics.Init(ctx, prometheus.DefaultRegisterer, apiV1.K8sClient)
+	namespaces := metrics.NamespaceFilter{
+		Allow: apiV1.Config.MetricsNamespaceAllow,
+		Deny:  apiV1.Config.MetricsNamespaceDeny,
+	}
+	metrics.Init(ctx, prometheus.DefaultRegisterer, apiV1.Clusters, namespaces)
+	if apiV1.History != nil {
+		metrics.InitSLO(prometheus.DefaultRegisterer, apiV1.Clusters, apiV1.History, namespaces)
+	}
 	mux := http.NewServeMux()
 
 	h := NewHandler()
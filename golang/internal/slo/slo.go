@@ -0,0 +1,146 @@
+// Package slo computes deployment availability from recorded health transitions.
+package slo
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/history"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// AnnotationTarget declares the availability target of a deployment in percent, e.g. "99.9".
+const AnnotationTarget = "sre.tyk.io/slo-target"
+
+// Window is a rolling period ending now.
+type Window struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Windows are the periods every deployment is evaluated over.
+var Windows = []Window{
+	{Name: "7d", Duration: 7 * 24 * time.Hour},
+	{Name: "30d", Duration: 30 * 24 * time.Hour},
+}
+
+// WindowReport is the availability of a deployment over one window.
+// Percentages are nil when the window holds no samples, budget fields when there is no target.
+type WindowReport struct {
+	Window string `json:"window"`
+	// Healthy share of the observed time, in percent
+	Availability *float64 `json:"availability"`
+	// Time covered by samples, shorter than the window for new deployments
+	ObservedSeconds  int64 `json:"observed_seconds"`
+	UnhealthySeconds int64 `json:"unhealthy_seconds"`
+	// Share of the error budget left, in percent, negative once exhausted
+	ErrorBudgetRemaining *float64 `json:"error_budget_remaining,omitempty"`
+	// Observed error rate over the allowed one, above 1 exhausts the budget before the window ends
+	BurnRate *float64 `json:"burn_rate,omitempty"`
+}
+
+// DeploymentSLO is the availability of a deployment over every window.
+type DeploymentSLO struct {
+	Cluster   string   `json:"cluster"`
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Target    *float64 `json:"target,omitempty"`
+	// Set when the target annotation cannot be parsed
+	TargetError string         `json:"target_error,omitempty"`
+	Windows     []WindowReport `json:"windows"`
+}
+
+// Target reads the target annotation, false when the deployment declares none.
+func Target(d *appsv1.Deployment) (float64, bool, error) {
+	value, ok := d.Annotations[AnnotationTarget]
+	if !ok {
+		return 0, false, nil
+	}
+
+	target, err := strconv.ParseFloat(value, 64)
+	if err != nil || target <= 0 || target >= 100 {
+		return 0, false, fmt.Errorf("invalid %s %q, expected a percentage between 0 and 100 exclusive", AnnotationTarget, value)
+	}
+	return target, true, nil
+}
+
+// Durations sums the time observed, and observed unhealthy, between start and end.
+// Each transition holds until the next one; time before the first and after a deletion is not observed.
+func Durations(transitions []history.Transition, start, end time.Time) (observed, unhealthy time.Duration) {
+	for i, t := range transitions {
+		from := t.Time
+		if from.Before(start) {
+			from = start
+		}
+		to := end
+		if i+1 < len(transitions) && transitions[i+1].Time.Before(end) {
+			to = transitions[i+1].Time
+		}
+		if t.Deleted || !to.After(from) {
+			continue
+		}
+
+		observed += to.Sub(from)
+		if !t.Healthy {
+			unhealthy += to.Sub(from)
+		}
+	}
+	return observed, unhealthy
+}
+
+// Evaluate reports the window ending at now, target in percent or nil.
+func Evaluate(transitions []history.Transition, window Window, now time.Time, target *float64) WindowReport {
+	observed, unhealthy := Durations(transitions, now.Add(-window.Duration), now)
+	report := WindowReport{
+		Window:           window.Name,
+		ObservedSeconds:  int64(observed.Seconds()),
+		UnhealthySeconds: int64(unhealthy.Seconds()),
+	}
+	if observed <= 0 {
+		return report
+	}
+
+	errorRate := unhealthy.Seconds() / observed.Seconds()
+	availability := (1 - errorRate) * 100
+	report.Availability = &availability
+
+	if target != nil {
+		burnRate := errorRate / (1 - *target/100)
+		remaining := (1 - burnRate) * 100
+		report.BurnRate = &burnRate
+		report.ErrorBudgetRemaining = &remaining
+	}
+	return report
+}
+
+// Compute evaluates deployments of a cluster over every window from the recorded history.
+func Compute(store *history.Store, cluster string, deployments []appsv1.Deployment, now time.Time) ([]DeploymentSLO, error) {
+	longest := Windows[0].Duration
+	for _, w := range Windows {
+		longest = max(longest, w.Duration)
+	}
+
+	reports := make([]DeploymentSLO, 0, len(deployments))
+	for _, d := range deployments {
+		key := history.Key{Cluster: cluster, Namespace: d.Namespace, Name: d.Name}
+		transitions, err := store.Range(key, now.Add(-longest), now)
+		if err != nil {
+			return nil, err
+		}
+
+		report := DeploymentSLO{Cluster: cluster, Namespace: d.Namespace, Name: d.Name, Windows: []WindowReport{}}
+		target, ok, err := Target(&d)
+		if err != nil {
+			report.TargetError = err.Error()
+		} else if ok {
+			report.Target = &target
+		}
+
+		for _, w := range Windows {
+			report.Windows = append(report.Windows, Evaluate(transitions, w, now, report.Target))
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
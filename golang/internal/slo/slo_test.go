@@ -0,0 +1,116 @@
+package slo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moemoeq/tyk-sre-app/internal/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var now = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+func TestTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation map[string]string
+		target     float64
+		ok         bool
+		err        bool
+	}{
+		{"No annotation", nil, 0, false, false},
+		{"Valid", map[string]string{AnnotationTarget: "99.9"}, 99.9, true, false},
+		{"Not a number", map[string]string{AnnotationTarget: "three nines"}, 0, false, true},
+		{"100 leaves no budget", map[string]string{AnnotationTarget: "100"}, 0, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, ok, err := Target(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotation}})
+			assert.Equal(t, tt.target, target)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.err, err != nil)
+		})
+	}
+}
+
+func TestDurations(t *testing.T) {
+	transitions := []history.Transition{
+		// state at start, before the window
+		{Time: now.Add(-10 * time.Hour), Healthy: true},
+		{Time: now.Add(-4 * time.Hour), Healthy: false},
+		{Time: now.Add(-3 * time.Hour), Healthy: true},
+		{Time: now.Add(-2 * time.Hour), Deleted: true},
+		{Time: now.Add(-time.Hour), Healthy: true},
+	}
+
+	observed, unhealthy := Durations(transitions, now.Add(-6*time.Hour), now)
+	// 6h window minus the hour the deployment was deleted
+	assert.Equal(t, 5*time.Hour, observed)
+	assert.Equal(t, time.Hour, unhealthy)
+
+	observed, unhealthy = Durations(nil, now.Add(-time.Hour), now)
+	assert.Zero(t, observed)
+	assert.Zero(t, unhealthy)
+}
+
+func TestEvaluate(t *testing.T) {
+	window := Window{Name: "7d", Duration: 7 * 24 * time.Hour}
+	transitions := []history.Transition{
+		{Time: now.Add(-window.Duration), Healthy: true},
+		// 1% of the window
+		{Time: now.Add(-window.Duration / 2), Healthy: false},
+		{Time: now.Add(-window.Duration/2 + window.Duration/100), Healthy: true},
+	}
+
+	target := 99.5
+	report := Evaluate(transitions, window, now, &target)
+	require.NotNil(t, report.Availability)
+	assert.InDelta(t, 99.0, *report.Availability, 1e-9)
+	assert.InDelta(t, 2.0, *report.BurnRate, 1e-9)
+	assert.InDelta(t, -100.0, *report.ErrorBudgetRemaining, 1e-9)
+	assert.Equal(t, int64(window.Duration.Seconds()), report.ObservedSeconds)
+
+	report = Evaluate(transitions, window, now, nil)
+	assert.NotNil(t, report.Availability)
+	assert.Nil(t, report.BurnRate)
+	assert.Nil(t, report.ErrorBudgetRemaining)
+
+	report = Evaluate(nil, window, now, &target)
+	assert.Nil(t, report.Availability)
+	assert.Nil(t, report.BurnRate)
+}
+
+func TestCompute(t *testing.T) {
+	store, err := history.Open(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	key := history.Key{Cluster: "prod", Namespace: "default", Name: "web"}
+	require.NoError(t, store.Record(key, history.Transition{Time: now.Add(-60 * 24 * time.Hour), Healthy: true}))
+	require.NoError(t, store.Record(key, history.Transition{Time: now.Add(-10 * 24 * time.Hour), Healthy: false}))
+	require.NoError(t, store.Record(key, history.Transition{Time: now.Add(-9 * 24 * time.Hour), Healthy: true}))
+
+	deployments := []appsv1.Deployment{
+		{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Annotations: map[string]string{AnnotationTarget: "99"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default", Annotations: map[string]string{AnnotationTarget: "high"}}},
+	}
+	reports, err := Compute(store, "prod", deployments, now)
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+
+	web := reports[0]
+	assert.Equal(t, 99.0, *web.Target)
+	require.Len(t, web.Windows, 2)
+	// the unhealthy day is outside the 7 day window
+	assert.InDelta(t, 100.0, *web.Windows[0].Availability, 1e-9)
+	assert.InDelta(t, 100*29.0/30, *web.Windows[1].Availability, 1e-9)
+	assert.Less(t, *web.Windows[1].ErrorBudgetRemaining, 0.0)
+
+	api := reports[1]
+	assert.Nil(t, api.Target)
+	assert.NotEmpty(t, api.TargetError)
+	assert.Nil(t, api.Windows[0].Availability)
+}